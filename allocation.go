@@ -0,0 +1,145 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net"
+	"strings"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// IP address allocation types
+const (
+	AllocationTypeVM        = "vmAllocated"
+	AllocationTypeVSM       = "vsmAllocated"
+	AllocationTypeNatRouted = "natRouted"
+)
+
+const (
+	vmType   = "application/vnd.vmware.vcloud.vm+xml"
+	vappType = "application/vnd.vmware.vcloud.vApp+xml"
+)
+
+// AllocatedAddress is an ip address in use on a network.
+type AllocatedAddress struct {
+	IPAddress      string
+	AllocationType string
+	Deployed       bool
+	VMHref         string
+	VAppHref       string
+}
+
+// AllocatedAddresses returns the addresses vCloud has allocated on the
+// network, along with the vm or vapp each is allocated to.
+func (n *Network) AllocatedAddresses() ([]AllocatedAddress, error) {
+	href := findActionLink(n.Links, "allocatedAddresses")
+	if href == "" {
+		href = strings.TrimSuffix(n.Href, "/") + "/allocatedAddresses"
+	}
+
+	resp, err := n.Connector.Get(href)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var list t.AllocatedIPAddressList
+	err = xml.Unmarshal(*data, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]AllocatedAddress, len(list.IPAddresses))
+	for i, ip := range list.IPAddresses {
+		addresses[i] = AllocatedAddress{
+			IPAddress:      ip.IPAddress,
+			AllocationType: ip.AllocationType,
+			Deployed:       ip.IsDeployed,
+		}
+
+		for _, link := range ip.Links {
+			switch link.Type {
+			case vmType:
+				addresses[i].VMHref = link.Href
+			case vappType:
+				addresses[i].VAppHref = link.Href
+			}
+		}
+	}
+
+	return addresses, nil
+}
+
+// FreeAddresses returns every address in the network's static ip ranges that
+// is not allocated.
+func (n *Network) FreeAddresses() ([]string, error) {
+	var free []string
+
+	err := n.eachFreeAddress(func(ip net.IP) bool {
+		free = append(free, ip.String())
+		return true
+	})
+
+	return free, err
+}
+
+// NextAvailableIP returns the first address in the network's static ip
+// ranges that is not allocated.
+func (n *Network) NextAvailableIP() (string, error) {
+	var next string
+
+	err := n.eachFreeAddress(func(ip net.IP) bool {
+		next = ip.String()
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if next == "" {
+		return "", fmt.Errorf("network %s has no free ip addresses", n.Name)
+	}
+
+	return next, nil
+}
+
+// eachFreeAddress calls fn for each unallocated address in the network's
+// static ip ranges until it returns false.
+func (n *Network) eachFreeAddress(fn func(ip net.IP) bool) error {
+	allocated, err := n.AllocatedAddresses()
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool, len(allocated))
+	for _, a := range allocated {
+		if ip := net.ParseIP(a.IPAddress); ip != nil {
+			used[ip.String()] = true
+		}
+	}
+
+	for _, scope := range n.IPScopes() {
+		for _, r := range scope.IPRanges.IPRange {
+			start, end, err := parseIPRange(r.StartAddress, r.EndAddress)
+			if err != nil {
+				return err
+			}
+
+			for ip := start; ; ip = nextIP(ip) {
+				if !used[ip.String()] && !fn(ip) {
+					return nil
+				}
+				if ip.Equal(end) {
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
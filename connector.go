@@ -2,13 +2,35 @@ package vcloud
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+const (
+	defaultLoginVersion = "5.1"
+	defaultAPIVersion   = "5.5"
 )
 
+// supportedVersions lists the api versions this library can talk, oldest
+// first. Elements added after 5.5 are only sent once the negotiated version
+// supports them, see Connector.SupportsVersion.
+var supportedVersions = []string{
+	"5.1", "5.5", "5.6", "9.0", "9.1", "9.5", "9.7",
+	"27.0", "29.0", "30.0", "31.0", "32.0", "33.0",
+}
+
 // Config ...
 type Config struct {
 	URL           string
@@ -16,6 +38,9 @@ type Config struct {
 	Password      string
 	Debug         bool
 	SSLSkipVerify bool
+	// APIVersion pins the api version to use. When empty, the highest
+	// version supported by both the library and the server is used.
+	APIVersion string
 }
 
 // Connector ...
@@ -23,6 +48,23 @@ type Connector struct {
 	Config    *Config
 	Client    *http.Client
 	AuthToken string
+	// OnReauthenticate is called whenever the connector re-authenticates
+	// after its session has expired, with the result of the attempt.
+	OnReauthenticate func(err error)
+	// RetryPolicy is used to retry requests that fail with a transient
+	// error. Requests are not retried when it is nil.
+	RetryPolicy *RetryPolicy
+	ctx         context.Context
+	session     *session
+}
+
+// session holds the auth token shared by a connector and any copies made
+// with WithContext, so that a re-authentication is only performed once.
+type session struct {
+	mu       sync.Mutex
+	token    string
+	version  string
+	loginURL string
 }
 
 // NewConnector ...
@@ -33,125 +75,499 @@ func NewConnector(config *Config) *Connector {
 	}
 	connector.Config = config
 	connector.Client = &http.Client{Transport: tr}
+	connector.session = &session{}
 	return &connector
 }
 
+// WithContext returns a shallow copy of the connector that uses ctx for all
+// of its requests. Entities created from the returned connector, and any
+// tasks they return, inherit the context.
+func (c *Connector) WithContext(ctx context.Context) *Connector {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// Context returns the connector's context, which defaults to
+// context.Background.
+func (c *Connector) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
 // Authenticate ...
 func (c *Connector) Authenticate() error {
-	url := fmt.Sprintf("https://%s/api/sessions", c.Config.URL)
+	return c.AuthenticateWithContext(c.Context())
+}
 
-	req, err := http.NewRequest("POST", url, nil)
+// AuthenticateWithContext ...
+func (c *Connector) AuthenticateWithContext(ctx context.Context) error {
+	version, loginURL, err := c.negotiateVersion(ctx)
 	if err != nil {
 		return err
 	}
 
-	req.SetBasicAuth(c.Config.Username, c.Config.Password)
-	req.Header.Set("accept", "application/*+xml;version=5.1")
-	resp, err := c.Client.Do(req)
+	token, err := c.login(ctx, loginURL, version)
 	if err != nil {
 		return err
 	}
 
-	if resp.StatusCode != 200 {
-		return newError(resp)
+	if c.session != nil {
+		c.session.mu.Lock()
+		defer c.session.mu.Unlock()
+		c.session.token = token
+		c.session.version = version
+		c.session.loginURL = loginURL
 	}
 
-	c.AuthToken = resp.Header.Get("x-vcloud-authorization")
+	c.AuthToken = token
 	return nil
 }
 
-// Get ...
-func (c *Connector) Get(url string) (*http.Response, error) {
-	req, err := c.newRequest("GET", url, nil)
+// APIVersion returns the api version negotiated with the server.
+func (c *Connector) APIVersion() string {
+	_, version := c.state()
+	return version
+}
+
+// SupportsVersion reports whether the negotiated api version is at least
+// the given version.
+func (c *Connector) SupportsVersion(version string) bool {
+	return compareVersions(c.APIVersion(), version) >= 0
+}
+
+func (c *Connector) negotiateVersion(ctx context.Context) (string, string, error) {
+	if c.session != nil {
+		c.session.mu.Lock()
+		version, loginURL := c.session.version, c.session.loginURL
+		c.session.mu.Unlock()
+		if version != "" {
+			return version, loginURL, nil
+		}
+	}
+
+	url := fmt.Sprintf("https://%s/api/versions", c.Config.URL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
 	resp, err := c.Client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, newError(resp)
+		return "", "", newError(resp)
 	}
 
-	return resp, nil
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return "", "", err
+	}
+
+	versions := t.SupportedVersions{}
+	err = xml.Unmarshal(*data, &versions)
+	if err != nil {
+		return "", "", err
+	}
+
+	var selected *t.VersionInfo
+	for i, v := range versions.VersionInfo {
+		if c.Config.APIVersion != "" {
+			if v.Version == c.Config.APIVersion {
+				selected = &versions.VersionInfo[i]
+			}
+			continue
+		}
+
+		if !isSupportedVersion(v.Version) {
+			continue
+		}
+
+		if selected == nil || compareVersions(v.Version, selected.Version) > 0 {
+			selected = &versions.VersionInfo[i]
+		}
+	}
+
+	if selected == nil {
+		if c.Config.APIVersion != "" {
+			return "", "", fmt.Errorf("api version %s is not supported by the server", c.Config.APIVersion)
+		}
+		return "", "", errors.New("could not find an api version supported by both the library and the server")
+	}
+
+	return selected.Version, selected.LoginURL, nil
 }
 
-// Post ...
-func (c *Connector) Post(url string, data []byte, contentType string) (*http.Response, error) {
-	req, err := c.newRequest("POST", url, bytes.NewBuffer(data))
+func (c *Connector) login(ctx context.Context, loginURL, version string) (string, error) {
+	if loginURL == "" {
+		loginURL = fmt.Sprintf("https://%s/api/sessions", c.Config.URL)
+	}
+
+	if version == "" {
+		version = defaultLoginVersion
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", loginURL, nil)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	req.Header.Set("Content-Type", contentType)
+	req.SetBasicAuth(c.Config.Username, c.Config.Password)
+	req.Header.Set("accept", "application/*+xml;version="+version)
 	resp, err := c.Client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	if resp.StatusCode != 201 {
-		return nil, newError(resp)
+	if resp.StatusCode != 200 {
+		return "", newError(resp)
 	}
 
-	return resp, nil
+	resp.Body.Close()
+
+	return resp.Header.Get("x-vcloud-authorization"), nil
+}
+
+// reauthenticate replaces an expired token. Concurrent callers holding the
+// same expired token wait for a single login rather than each starting one.
+// The hook is called once the session is unlocked, so it may use the
+// connector.
+func (c *Connector) reauthenticate(ctx context.Context, expired string) error {
+	c.session.mu.Lock()
+
+	current := c.session.token
+	if current == "" {
+		current = c.AuthToken
+	}
+
+	if current != expired {
+		c.session.mu.Unlock()
+		return nil
+	}
+
+	token, err := c.login(ctx, c.session.loginURL, c.session.version)
+	if err == nil {
+		c.session.token = token
+		c.AuthToken = token
+	}
+
+	c.session.mu.Unlock()
+
+	if c.OnReauthenticate != nil {
+		c.OnReauthenticate(err)
+	}
+
+	return err
+}
+
+// state returns the current auth token and api version.
+func (c *Connector) state() (string, string) {
+	if c.session == nil {
+		return c.AuthToken, defaultAPIVersion
+	}
+
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+
+	token := c.session.token
+	if token == "" {
+		token = c.AuthToken
+	}
+
+	version := c.session.version
+	if version == "" {
+		version = defaultAPIVersion
+	}
+
+	return token, version
+}
+
+// Get ...
+func (c *Connector) Get(url string) (*http.Response, error) {
+	return c.GetWithContext(c.Context(), url)
+}
+
+// GetWithContext ...
+func (c *Connector) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	return c.Request(ctx, "GET", url, nil, &RequestOptions{ExpectedStatus: []int{200}})
+}
+
+// Post ...
+func (c *Connector) Post(url string, data []byte, contentType string) (*http.Response, error) {
+	return c.PostWithContext(c.Context(), url, data, contentType)
+}
+
+// PostWithContext ...
+func (c *Connector) PostWithContext(ctx context.Context, url string, data []byte, contentType string) (*http.Response, error) {
+	return c.Request(ctx, "POST", url, data, &RequestOptions{ContentType: contentType})
+}
+
+// PostTask ...
+func (c *Connector) PostTask(url string, data []byte, contentType string) (*Task, error) {
+	return c.PostTaskWithContext(c.Context(), url, data, contentType)
+}
+
+// PostTaskWithContext ...
+func (c *Connector) PostTaskWithContext(ctx context.Context, url string, data []byte, contentType string) (*Task, error) {
+	return c.RequestTask(ctx, "POST", url, data, &RequestOptions{ContentType: contentType})
 }
 
 // Put ...
 func (c *Connector) Put(url string, data []byte, contentType string) (*http.Response, error) {
-	req, err := c.newRequest("PUT", url, bytes.NewBuffer(data))
+	return c.PutWithContext(c.Context(), url, data, contentType)
+}
+
+// PutWithContext ...
+func (c *Connector) PutWithContext(ctx context.Context, url string, data []byte, contentType string) (*http.Response, error) {
+	return c.Request(ctx, "PUT", url, data, &RequestOptions{ContentType: contentType})
+}
+
+// PutTask ...
+func (c *Connector) PutTask(url string, data []byte, contentType string) (*Task, error) {
+	return c.PutTaskWithContext(c.Context(), url, data, contentType)
+}
+
+// PutTaskWithContext ...
+func (c *Connector) PutTaskWithContext(ctx context.Context, url string, data []byte, contentType string) (*Task, error) {
+	return c.RequestTask(ctx, "PUT", url, data, &RequestOptions{ContentType: contentType})
+}
+
+// Delete ...
+func (c *Connector) Delete(uri string) error {
+	return c.DeleteWithContext(c.Context(), uri)
+}
+
+// DeleteWithContext ...
+func (c *Connector) DeleteWithContext(ctx context.Context, uri string) error {
+	resp, err := c.Request(ctx, "DELETE", uri, nil, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	req.Header.Set("Content-Type", contentType)
-	resp, err := c.Client.Do(req)
+	resp.Body.Close()
+
+	return nil
+}
+
+// DeleteTask ...
+func (c *Connector) DeleteTask(uri string) (*Task, error) {
+	return c.DeleteTaskWithContext(c.Context(), uri)
+}
+
+// DeleteTaskWithContext ...
+func (c *Connector) DeleteTaskWithContext(ctx context.Context, uri string) (*Task, error) {
+	return c.RequestTask(ctx, "DELETE", uri, nil, nil)
+}
+
+// RequestOptions ...
+type RequestOptions struct {
+	ContentType string
+	// ExpectedStatus lists the status codes treated as success. Any 2xx
+	// status is accepted when it is empty.
+	ExpectedStatus []int
+}
+
+// Request performs a request, returning an error if the response status is
+// not one of the expected statuses.
+func (c *Connector) Request(ctx context.Context, method, url string, data []byte, opts *RequestOptions) (*http.Response, error) {
+	if opts == nil {
+		opts = &RequestOptions{}
+	}
+
+	resp, err := c.do(ctx, method, url, data, opts.ContentType)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 201 {
+	if !opts.expects(resp.StatusCode) {
 		return nil, newError(resp)
 	}
 
 	return resp, nil
 }
 
-// Delete ...
-func (c *Connector) Delete(uri string) error {
-	req, err := c.newRequest("DELETE", uri, nil)
+// ErrNoTask is returned when a request that should start a task succeeds
+// without returning one, such as a 204 No Content response.
+var ErrNoTask = errors.New("response did not include a task")
+
+// RequestTask performs a request that is expected to return a Task.
+// ErrNoTask is returned if the response has no content.
+func (c *Connector) RequestTask(ctx context.Context, method, url string, data []byte, opts *RequestOptions) (*Task, error) {
+	resp, err := c.Request(ctx, method, url, data, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := c.Client.Do(req)
+	tdata, err := ParseResponse(resp)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		return newError(resp)
+	if len(bytes.TrimSpace(*tdata)) == 0 {
+		return nil, ErrNoTask
 	}
 
-	return nil
+	task := Task{}
+	err = xml.Unmarshal(*tdata, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Connector = c
+
+	return &task, nil
 }
 
-func (c *Connector) newRequest(method string, url string, payload io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, payload)
+func (o *RequestOptions) expects(status int) bool {
+	if len(o.ExpectedStatus) == 0 {
+		return status >= 200 && status <= 299
+	}
 
-	req.Header.Set("accept", "application/*+xml;version=5.5")
-	req.Header.Set("x-vcloud-authorization", c.AuthToken)
+	for _, s := range o.ExpectedStatus {
+		if s == status {
+			return true
+		}
+	}
 
-	return req, err
+	return false
 }
 
-func newError(resp *http.Response) error {
-	data, err := ParseResponse(resp)
+func (c *Connector) do(ctx context.Context, method string, url string, data []byte, contentType string) (*http.Response, error) {
+	policy := c.RetryPolicy
+
+	for retry := 1; ; retry++ {
+		resp, err := c.attempt(ctx, method, url, data, contentType)
+		if policy == nil || retry >= policy.MaxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		if err == nil {
+			if resp.StatusCode < 400 {
+				return resp, nil
+			}
+
+			// read the error so it can be classified, leaving the body
+			// intact for the caller if the request is not retried
+			body, rerr := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if rerr != nil {
+				return nil, rerr
+			}
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			err = newError(&http.Response{
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			})
+		}
+
+		if !replayable(method, err) || !policy.retryable(err) {
+			if resp != nil {
+				return resp, nil
+			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(policy.delay(retry, resp)):
+		}
+	}
+}
+
+func (c *Connector) attempt(ctx context.Context, method string, url string, data []byte, contentType string) (*http.Response, error) {
+	token, version := c.state()
+
+	resp, err := c.send(ctx, method, url, data, contentType, token, version)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized || token == "" || c.session == nil {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	err = c.reauthenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	token, version = c.state()
+
+	return c.send(ctx, method, url, data, contentType, token, version)
+}
+
+func (c *Connector) send(ctx context.Context, method string, url string, data []byte, contentType string, token, version string) (*http.Response, error) {
+	var payload io.Reader
+	if data != nil {
+		payload = bytes.NewReader(data)
 	}
-	vcloudErr := ParseError(data)
-	return errors.New(vcloudErr.Message)
+
+	req, err := c.newRequest(ctx, method, url, payload, token, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return c.Client.Do(req)
+}
+
+func (c *Connector) newRequest(ctx context.Context, method string, url string, payload io.Reader, token, version string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("accept", "application/*+xml;version="+version)
+	req.Header.Set("x-vcloud-authorization", token)
+
+	return req, nil
+}
+
+func isSupportedVersion(version string) bool {
+	for _, v := range supportedVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// compareVersions compares two dotted api versions, returning -1, 0 or 1.
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+
+	return 0
 }
@@ -2,6 +2,7 @@ package vcloud
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -10,26 +11,83 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 var (
-	mux    *http.ServeMux
-	server *httptest.Server
+	mux             *http.ServeMux
+	server          *httptest.Server
+	sessionRequests int32
+	flakyRequests   int32
+	captureMu       sync.Mutex
+	captured        capturedRequest
 )
 
+// capturedRequest records the last request received by taskCaptureHandler,
+// so tests can inspect the body the client marshalled.
+type capturedRequest struct {
+	Method      string
+	Path        string
+	ContentType string
+	Body        []byte
+}
+
+func lastRequest() capturedRequest {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+	return captured
+}
+
 func setup() {
 	router := httprouter.New()
 
+	router.GET("/api/versions", versionsHandler)
 	router.POST("/api/sessions", sessionHandler)
 	router.GET("/test", getHandler)
+	router.GET("/session", sessionCheckHandler)
+	router.GET("/flaky", flakyHandler)
+	router.POST("/busy", busyHandler)
+	router.GET("/dropped", droppedHandler)
+	router.POST("/dropped", droppedHandler)
+	router.POST("/accepted", acceptedHandler)
+	router.PUT("/accepted", acceptedHandler)
+	router.DELETE("/accepted", acceptedHandler)
+	router.DELETE("/nocontent", noContentHandler)
 	router.POST("/test", postHandler)
 	router.PUT("/test", postHandler)
 	router.DELETE("/test", deleteHandler)
-	router.NotFound = notFoundHandler
+	router.GET("/api/query", queryHandler)
+	router.GET("/api/task/:id", taskHandler)
+	router.POST("/api/task/:id/action/cancel", taskCancelHandler)
+	router.POST("/api/vdc/:id/action/instantiateVAppTemplate", instantiateVAppHandler)
+	router.GET("/api/vApp/:id", vmHandler)
+	router.PUT("/api/vApp/:id/virtualHardwareSection/:resource", taskCaptureHandler)
+	router.POST("/api/vApp/:id/power/action/:action", vappActionHandler)
+	router.POST("/api/vApp/:id/action/:action", vappActionHandler)
+	router.GET("/api/admin/edgeGateway/:id", edgeGatewayHandler)
+	router.POST("/api/admin/edgeGateway/:id/action/configureServices", taskCaptureHandler)
+	router.GET("/api/network/:id", networkHandler)
+	router.GET("/api/network/:id/allocatedAddresses", allocatedAddressesHandler)
+	router.PUT("/api/admin/network/:id", taskCaptureHandler)
+	router.DELETE("/api/admin/network/:id", acceptedHandler)
+	router.POST("/api/admin/vdc/:id/networks", networkCreationHandler)
+	router.GET("/api/admin/extension/externalNetworkReferences", externalNetworkReferencesHandler)
+	router.GET("/api/admin/extension/externalnet/:id", externalNetworkHandler)
+	router.PUT("/api/admin/extension/externalnet/:id", taskCaptureHandler)
+	router.DELETE("/api/admin/extension/externalnet/:id", taskCaptureHandler)
+	router.POST("/api/admin/extension/externalnets", echoHandler)
+	router.GET("/api/admin/extension/networkPoolReferences", networkPoolReferencesHandler)
+	router.GET("/api/admin/extension/networkPool/:id", networkPoolHandler)
+	router.PUT("/api/admin/extension/networkPool/:id", taskCaptureHandler)
+	router.DELETE("/api/admin/extension/networkPool/:id", taskCaptureHandler)
+	router.POST("/api/admin/extension/networkPools", echoHandler)
+	router.NotFound = http.HandlerFunc(notFoundHandler)
 
 	server = httptest.NewTLSServer(router)
 }
@@ -65,10 +123,21 @@ func loadFixture(file string) ([]byte, error) {
 	return bytes, nil
 }
 
+func versionsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprint(w, `<SupportedVersions xmlns="http://www.vmware.com/vcloud/versions">`)
+	for _, v := range []string{"5.1", "5.5", "99.0"} {
+		fmt.Fprintf(w, `<VersionInfo deprecated="false"><Version>%s</Version><LoginUrl>https://%s/api/sessions</LoginUrl></VersionInfo>`, v, r.Host)
+	}
+	fmt.Fprint(w, `</SupportedVersions>`)
+}
+
 func sessionHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	atomic.AddInt32(&sessionRequests, 1)
 	w.Header().Set("Content-Type", "application/xml")
 	user, pass, _ := r.BasicAuth()
-	if r.Header.Get("accept") == "application/*+xml;version=5.1" &&
+	accept := r.Header.Get("accept")
+	if (accept == "application/*+xml;version=5.1" || accept == "application/*+xml;version=5.5") &&
 		user == "test@test" &&
 		pass == "test" {
 		w.Header().Set("x-vcloud-authorization", "test")
@@ -89,6 +158,89 @@ func auth(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+func sessionCheckHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if r.Header.Get("x-vcloud-authorization") != "test" {
+		http.Error(w, "", 401)
+		return
+	}
+	w.Write([]byte("OK"))
+}
+
+func flakyHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+	if atomic.AddInt32(&flakyRequests, 1) < 3 {
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "Service Unavailable", 503)
+		return
+	}
+	w.Write([]byte("OK"))
+}
+
+func busyHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	if atomic.AddInt32(&flakyRequests, 1) < 2 {
+		http.Error(w, `<Error minorErrorCode="BUSY_ENTITY" message="The entity is busy completing an operation" majorErrorCode="400"/>`, 400)
+		return
+	}
+	if string(body) != "test request" {
+		http.Error(w, "", 400)
+		return
+	}
+	w.WriteHeader(201)
+}
+
+// droppedHandler closes the connection without responding, as if it had
+// been reset after the request was processed.
+func droppedHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+	atomic.AddInt32(&flakyRequests, 1)
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func acceptedHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.vmware.vcloud.task+xml")
+	w.WriteHeader(202)
+	fmt.Fprintf(w, `<Task xmlns="http://www.vmware.com/vcloud/v1.5" name="task" status="queued" operationName="vappDeploy" href="https://%s/api/task/success"/>`, r.Host)
+}
+
+// taskCaptureHandler records the request and accepts it with a task.
+func taskCaptureHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	captureMu.Lock()
+	captured = capturedRequest{
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		ContentType: r.Header.Get("Content-Type"),
+		Body:        *parseRequest(r),
+	}
+	captureMu.Unlock()
+
+	acceptedHandler(w, r, ps)
+}
+
+func noContentHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+	w.WriteHeader(204)
+}
+
 func notFoundHandler(w http.ResponseWriter, r *http.Request) {
 	payload, _ := loadFixture("fixtures/resourcenotfound.xml")
 	http.Error(w, string(payload), 404)
@@ -98,6 +250,7 @@ func getHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	if !auth(w, r) {
 		return
 	}
+	w.Header().Set("x-test-accept", r.Header.Get("accept"))
 	w.Write([]byte("OK"))
 }
 
@@ -161,6 +314,14 @@ func TestAuthenticate(t *testing.T) {
 			Convey("Auth Token should not be stored", func() {
 				So(c.AuthToken, ShouldBeBlank)
 			})
+			Convey("Error should be an access forbidden error", func() {
+				var vErr *Error
+				So(errors.As(err, &vErr), ShouldBeTrue)
+				So(vErr.StatusCode, ShouldEqual, 403)
+				So(vErr.MinorErrorCode, ShouldEqual, ErrorCodeAccessForbidden)
+				So(IsAccessForbidden(err), ShouldBeTrue)
+				So(IsNotFound(err), ShouldBeFalse)
+			})
 		})
 	})
 
@@ -219,6 +380,15 @@ func TestGet(t *testing.T) {
 				So(err, ShouldNotBeNil)
 				So(message, ShouldEqual, "Resource not found")
 			})
+			Convey("The error should be a not found error", func() {
+				var vErr *Error
+				So(errors.As(err, &vErr), ShouldBeTrue)
+				So(vErr.StatusCode, ShouldEqual, 404)
+				So(vErr.MajorErrorCode, ShouldEqual, 404)
+				So(vErr.MinorErrorCode, ShouldEqual, ErrorCodeResourceNotFound)
+				So(IsNotFound(err), ShouldBeTrue)
+				So(IsBusyEntity(err), ShouldBeFalse)
+			})
 			Convey("There should be no response body", func() {
 				So(resp, ShouldBeNil)
 			})
@@ -380,3 +550,391 @@ func TestDelete(t *testing.T) {
 		})
 	})
 }
+
+func TestContext(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a request with a context", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		authErr := c.Authenticate()
+		href := fmt.Sprintf("https://%s/test", tsurl.Host)
+
+		Convey("When the context is active", func() {
+			resp, err := c.GetWithContext(context.Background(), href)
+			Convey("The request should succeed", func() {
+				So(authErr, ShouldBeNil)
+				So(err, ShouldBeNil)
+				So(resp, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the context has been cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			resp, err := c.GetWithContext(ctx, href)
+			Convey("The request should be aborted", func() {
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+				So(resp, ShouldBeNil)
+			})
+		})
+
+		Convey("When the connector is bound to a cancelled context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			resp, err := c.WithContext(ctx).Get(href)
+			Convey("The request should be aborted", func() {
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+				So(resp, ShouldBeNil)
+			})
+			Convey("The original connector should be unaffected", func() {
+				So(c.Context(), ShouldEqual, context.Background())
+			})
+		})
+	})
+}
+
+func TestReauthenticate(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given an expired session", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+		c.session.token = "expired"
+		href := fmt.Sprintf("https://%s/session", tsurl.Host)
+
+		var hookCalls int32
+		c.OnReauthenticate = func(err error) {
+			atomic.AddInt32(&hookCalls, 1)
+		}
+
+		atomic.StoreInt32(&sessionRequests, 0)
+
+		Convey("When making a request", func() {
+			resp, err := c.Get(href)
+			Convey("The request should be replayed with a new token", func() {
+				So(err, ShouldBeNil)
+				So(resp, ShouldNotBeNil)
+				So(c.AuthToken, ShouldEqual, "test")
+			})
+			Convey("The hook should be called", func() {
+				So(atomic.LoadInt32(&hookCalls), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When making concurrent requests", func() {
+			var wg sync.WaitGroup
+			errs := make([]error, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = c.WithContext(context.Background()).Get(href)
+				}(i)
+			}
+			wg.Wait()
+			Convey("All requests should succeed", func() {
+				for _, err := range errs {
+					So(err, ShouldBeNil)
+				}
+			})
+			Convey("Only one session should be created", func() {
+				So(atomic.LoadInt32(&sessionRequests), ShouldEqual, 1)
+				So(atomic.LoadInt32(&hookCalls), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the hook uses the connector", func() {
+			var version string
+			var hookErr error
+			c.OnReauthenticate = func(err error) {
+				version = c.APIVersion()
+				_, hookErr = c.Get(href)
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := c.Get(href)
+				done <- err
+			}()
+
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(3 * time.Second):
+				err = errors.New("request deadlocked")
+			}
+
+			Convey("The request should not deadlock", func() {
+				So(err, ShouldBeNil)
+				So(version, ShouldEqual, "5.5")
+				So(hookErr, ShouldBeNil)
+			})
+		})
+
+		Convey("When the credentials are no longer valid", func() {
+			cf.Password = "tset"
+			resp, err := c.Get(href)
+			Convey("The authentication error should be returned", func() {
+				So(IsAccessForbidden(err), ShouldBeTrue)
+				So(resp, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestVersionNegotiation(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a server supporting multiple api versions", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		href := fmt.Sprintf("https://%s/test", tsurl.Host)
+
+		Convey("When no version is pinned", func() {
+			c := NewConnector(&cf)
+			err := c.Authenticate()
+			resp, _ := c.Get(href)
+			Convey("The highest mutually supported version should be used", func() {
+				So(err, ShouldBeNil)
+				So(c.APIVersion(), ShouldEqual, "5.5")
+				So(resp.Header.Get("x-test-accept"), ShouldEqual, "application/*+xml;version=5.5")
+			})
+			Convey("Features can be gated on the version", func() {
+				So(c.SupportsVersion("5.1"), ShouldBeTrue)
+				So(c.SupportsVersion("5.5"), ShouldBeTrue)
+				So(c.SupportsVersion("5.6"), ShouldBeFalse)
+			})
+		})
+
+		Convey("When a supported version is pinned", func() {
+			cf.APIVersion = "5.1"
+			c := NewConnector(&cf)
+			err := c.Authenticate()
+			Convey("The pinned version should be used", func() {
+				So(err, ShouldBeNil)
+				So(c.APIVersion(), ShouldEqual, "5.1")
+			})
+		})
+
+		Convey("When an unsupported version is pinned", func() {
+			cf.APIVersion = "6.0"
+			c := NewConnector(&cf)
+			err := c.Authenticate()
+			Convey("An error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(c.AuthToken, ShouldBeBlank)
+			})
+		})
+	})
+
+	Convey("Given a server that no longer supports 5.x api versions", t, func() {
+		router := httprouter.New()
+		router.GET("/api/versions", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			fmt.Fprint(w, `<SupportedVersions xmlns="http://www.vmware.com/vcloud/versions">`)
+			for _, v := range []string{"30.0", "31.0", "99.0"} {
+				fmt.Fprintf(w, `<VersionInfo deprecated="false"><Version>%s</Version><LoginUrl>https://%s/api/sessions</LoginUrl></VersionInfo>`, v, r.Host)
+			}
+			fmt.Fprint(w, `</SupportedVersions>`)
+		})
+		router.POST("/api/sessions", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			if r.Header.Get("accept") != "application/*+xml;version=31.0" {
+				http.Error(w, "", 406)
+				return
+			}
+			w.Header().Set("x-vcloud-authorization", "test")
+		})
+		newer := httptest.NewTLSServer(router)
+		defer newer.Close()
+		newerURL, _ := url.Parse(newer.URL)
+
+		c := NewConnector(&Config{
+			URL:           newerURL.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		})
+		err := c.Authenticate()
+
+		Convey("The highest mutually supported version should be used", func() {
+			So(err, ShouldBeNil)
+			So(c.APIVersion(), ShouldEqual, "31.0")
+			So(c.SupportsVersion("30.0"), ShouldBeTrue)
+		})
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a connector with a retry policy", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+		c.RetryPolicy = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+		atomic.StoreInt32(&flakyRequests, 0)
+
+		Convey("When the service is temporarily unavailable", func() {
+			resp, err := c.Get(fmt.Sprintf("https://%s/flaky", tsurl.Host))
+			Convey("The request should be retried until it succeeds", func() {
+				So(err, ShouldBeNil)
+				So(resp, ShouldNotBeNil)
+				So(atomic.LoadInt32(&flakyRequests), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When the entity is busy", func() {
+			resp, err := c.Post(fmt.Sprintf("https://%s/busy", tsurl.Host), []byte("test request"), "application/xml")
+			Convey("The request body should be replayed", func() {
+				So(err, ShouldBeNil)
+				So(resp, ShouldNotBeNil)
+				So(atomic.LoadInt32(&flakyRequests), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the connection drops during a get", func() {
+			resp, err := c.Get(fmt.Sprintf("https://%s/dropped", tsurl.Host))
+			Convey("The request should be retried", func() {
+				So(err, ShouldNotBeNil)
+				So(resp, ShouldBeNil)
+				So(atomic.LoadInt32(&flakyRequests), ShouldBeGreaterThanOrEqualTo, 3)
+			})
+		})
+
+		Convey("When the connection drops during a post", func() {
+			resp, err := c.Post(fmt.Sprintf("https://%s/dropped", tsurl.Host), []byte("test request"), "application/xml")
+			Convey("The request should not be replayed", func() {
+				So(err, ShouldNotBeNil)
+				So(resp, ShouldBeNil)
+				So(atomic.LoadInt32(&flakyRequests), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the attempts are exhausted", func() {
+			c.RetryPolicy.MaxAttempts = 2
+			resp, err := c.Get(fmt.Sprintf("https://%s/flaky", tsurl.Host))
+			Convey("The last error should be returned", func() {
+				var vErr *Error
+				So(errors.As(err, &vErr), ShouldBeTrue)
+				So(vErr.StatusCode, ShouldEqual, 503)
+				So(resp, ShouldBeNil)
+			})
+		})
+
+		Convey("When the error is not transient", func() {
+			_, err := c.Get(fmt.Sprintf("https://%s/invalidtest", tsurl.Host))
+			Convey("The request should not be retried", func() {
+				So(IsNotFound(err), ShouldBeTrue)
+				So(err.Error(), ShouldEqual, "Resource not found")
+			})
+		})
+	})
+}
+
+func TestRequestTask(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given an asynchronous request", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+		href := fmt.Sprintf("https://%s/accepted", tsurl.Host)
+
+		Convey("When the server accepts a post", func() {
+			task, err := c.PostTask(href, nil, "")
+			Convey("The task should be returned", func() {
+				So(err, ShouldBeNil)
+				So(task.OperationName, ShouldEqual, "vappDeploy")
+				So(task.Status, ShouldEqual, TaskStatusQueued)
+				So(task.Connector, ShouldEqual, c)
+			})
+		})
+
+		Convey("When the server accepts a put with a context", func() {
+			task, err := c.PutTaskWithContext(context.Background(), href, []byte("test request"), "application/xml")
+			Convey("The task should be returned", func() {
+				So(err, ShouldBeNil)
+				So(task.Status, ShouldEqual, TaskStatusQueued)
+			})
+		})
+
+		Convey("When the context has been cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			task, err := c.PostTaskWithContext(ctx, href, nil, "")
+			Convey("The request should be aborted", func() {
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+				So(task, ShouldBeNil)
+			})
+			task, err = c.DeleteTaskWithContext(ctx, href)
+			Convey("The delete should be aborted", func() {
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+				So(task, ShouldBeNil)
+			})
+		})
+
+		Convey("When the server accepts a delete", func() {
+			task, err := c.DeleteTask(href)
+			Convey("The task should be returned", func() {
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+			})
+			Convey("A plain delete should also succeed", func() {
+				So(c.Delete(href), ShouldBeNil)
+			})
+		})
+
+		Convey("When the server returns no content", func() {
+			task, err := c.DeleteTask(fmt.Sprintf("https://%s/nocontent", tsurl.Host))
+			Convey("No task should be returned", func() {
+				So(err, ShouldEqual, ErrNoTask)
+				So(task, ShouldBeNil)
+			})
+		})
+
+		Convey("When the status does not match the expected statuses", func() {
+			resp, err := c.Request(context.Background(), "POST", href, nil, &RequestOptions{ExpectedStatus: []int{201}})
+			Convey("An error should be returned", func() {
+				var vErr *Error
+				So(errors.As(err, &vErr), ShouldBeTrue)
+				So(vErr.StatusCode, ShouldEqual, 202)
+				So(resp, ShouldBeNil)
+			})
+		})
+	})
+}
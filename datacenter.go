@@ -2,9 +2,9 @@ package vcloud
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"log"
-	"net/url"
 
 	t "git.r3labs.io/libraries/go-vcloud/types"
 )
@@ -13,6 +13,12 @@ const (
 	instantiateVAppTemplateParamsType = "application/vnd.vmware.vcloud.instantiateVAppTemplateParams+xml"
 )
 
+// Api versions that introduced the routed network interface options
+const (
+	subInterfaceVersion         = "5.6"
+	distributedInterfaceVersion = "27.0"
+)
+
 // Datacenter ...
 type Datacenter struct {
 	Connector         *Connector          `xml:"-"`
@@ -57,6 +63,11 @@ func (d *Datacenter) GetEdgeGateway(name string) (*EdgeGateway, error) {
 	return FindEdgeGateway(d.Connector, d.Href, name)
 }
 
+// EdgeGateways ...
+func (d *Datacenter) EdgeGateways() ([]t.EdgeGatewayRecord, error) {
+	return ListEdgeGateways(d.Connector, d.Href)
+}
+
 // VApps ...
 func (d *Datacenter) VApps() []t.Link {
 	var vapps []t.Link
@@ -80,13 +91,36 @@ func (d *Datacenter) GetVApp(name string) (*VApp, error) {
 }
 
 // CreateVApp ...
-func (d *Datacenter) CreateVApp(request *t.InstantiateVApp) {
+func (d *Datacenter) CreateVApp(request *t.InstantiateVApp) (*VApp, error) {
 	links := d.findLinks(instantiateVAppTemplateParamsType)
-	cvURL, err := url.Parse(links[0].Href)
+	if len(links) < 1 {
+		return nil, errors.New("could not find instantiate vapp template link for datacenter")
+	}
+
+	data, err := xml.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.Connector.Post(links[0].Href, data, instantiateVAppTemplateParamsType)
+	if err != nil {
+		return nil, err
+	}
+
+	vdata, err := ParseResponse(resp)
 	if err != nil {
-		fmt.Println(cvURL)
+		return nil, err
 	}
 
+	v := VApp{}
+	err = xml.Unmarshal(*vdata, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Connector = d.Connector
+
+	return &v, nil
 }
 
 // Networks ...
@@ -105,11 +139,20 @@ func (d *Datacenter) GetNetwork(name string) (*Network, error) {
 	return NewNetwork(d.Connector, href)
 }
 
-// CreateNetwork ...
+// CreateNetwork creates the network without waiting for it to be ready. The
+// returned network's tasks track its creation.
 func (d *Datacenter) CreateNetwork(n *Network) (*Network, error) {
 	links := d.findLinks(orgNetworkType)
+	if len(links) < 1 {
+		return nil, errors.New("could not find create network link for datacenter")
+	}
 	href := links[0].Href
 
+	err := n.checkIPScopesVersion(d.Connector)
+	if err != nil {
+		return nil, err
+	}
+
 	data, err := xml.Marshal(n)
 	if err != nil {
 		return nil, err
@@ -135,6 +178,182 @@ func (d *Datacenter) CreateNetwork(n *Network) (*Network, error) {
 	return &nw, nil
 }
 
+// CreateNetworkAndWait creates the network and waits for its creation tasks
+// to complete, returning the network once it is ready.
+func (d *Datacenter) CreateNetworkAndWait(n *Network) (*Network, error) {
+	nw, err := d.CreateNetwork(n)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range nw.GetTasks() {
+		err = task.Wait()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = nw.WaitReady()
+	if err != nil {
+		return nil, err
+	}
+
+	return nw, nil
+}
+
+// NetworkOptions are optional settings for networks created with
+// CreateRoutedNetwork, CreateIsolatedNetwork and CreateDirectNetwork.
+type NetworkOptions struct {
+	Description string
+	Shared      bool
+	// Distributed and SubInterface only apply to routed networks, can not
+	// be combined, and need api versions 27.0 and 5.6 respectively.
+	Distributed  bool
+	SubInterface bool
+}
+
+// CreateRoutedNetwork creates a network connected to an edge gateway.
+func (d *Datacenter) CreateRoutedNetwork(name string, gw *EdgeGateway, scope t.IPScope, opts *NetworkOptions) (*Network, error) {
+	if gw == nil || gw.Href == "" {
+		return nil, fmt.Errorf("routed network %s requires an edge gateway", name)
+	}
+
+	n, err := newNetwork(name, FenceModeNatRouted, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts != nil {
+		if opts.Distributed && opts.SubInterface {
+			return nil, fmt.Errorf("routed network %s can not be both distributed and a subinterface", name)
+		}
+		if opts.Distributed && !d.Connector.SupportsVersion(distributedInterfaceVersion) {
+			return nil, fmt.Errorf("routed network %s: distributed interfaces require api version %s, but %s was negotiated", name, distributedInterfaceVersion, d.Connector.APIVersion())
+		}
+		if opts.SubInterface && !d.Connector.SupportsVersion(subInterfaceVersion) {
+			return nil, fmt.Errorf("routed network %s: subinterfaces require api version %s, but %s was negotiated", name, subInterfaceVersion, d.Connector.APIVersion())
+		}
+		if opts.Distributed {
+			distributed := true
+			n.Configuration.DistributedInterface = &distributed
+		}
+		if opts.SubInterface {
+			subInterface := true
+			n.Configuration.SubInterface = &subInterface
+		}
+	}
+
+	scope.IsEnabled = true
+	err = n.AddIPScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	n.SetEdgeGateway(gw.Href, gw.Name)
+
+	return d.CreateNetwork(n)
+}
+
+// CreateIsolatedNetwork creates a network that is not connected to anything
+// outside the vdc. If dhcp is set, the vdc's dhcp service will serve its ip
+// range on the network.
+func (d *Datacenter) CreateIsolatedNetwork(name string, scope t.IPScope, dhcp *t.DhcpService, opts *NetworkOptions) (*Network, error) {
+	err := validateRoutedOptions(name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := newNetwork(name, FenceModeIsolated, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	scope.IsEnabled = true
+	err = n.AddIPScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if dhcp != nil {
+		if dhcp.IPRange == nil {
+			return nil, fmt.Errorf("isolated network %s dhcp service must specify an ip range", name)
+		}
+
+		service := *dhcp
+		service.IsEnabled = true
+
+		if service.DefaultLeaseTime == 0 {
+			service.DefaultLeaseTime = defaultDhcpLeaseTime
+		}
+
+		if service.MaxLeaseTime == 0 {
+			service.MaxLeaseTime = defaultDhcpMaxLeaseTime
+		}
+
+		err = validateDhcpPool(n, &t.DhcpPool{
+			LowIPAddress:     service.IPRange.StartAddress,
+			HighIPAddress:    service.IPRange.EndAddress,
+			DefaultLeaseTime: service.DefaultLeaseTime,
+			MaxLeaseTime:     service.MaxLeaseTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		n.ServiceConfig = &t.NetworkServiceConfig{DhcpService: &service}
+	}
+
+	return d.CreateNetwork(n)
+}
+
+// CreateDirectNetwork creates a network bridged directly onto an external
+// network, inheriting its ip configuration.
+func (d *Datacenter) CreateDirectNetwork(name string, parent t.Reference, opts *NetworkOptions) (*Network, error) {
+	if parent.Href == "" {
+		return nil, fmt.Errorf("direct network %s requires an external parent network", name)
+	}
+
+	err := validateRoutedOptions(name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := newNetwork(name, FenceModeBridged, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent.Type == "" {
+		parent.Type = externalNetworkType
+	}
+	n.Configuration.ParentNetwork = &parent
+
+	return d.CreateNetwork(n)
+}
+
+func newNetwork(name, fenceMode string, opts *NetworkOptions) (*Network, error) {
+	if name == "" {
+		return nil, errors.New("network name must not be empty")
+	}
+
+	n := &Network{Name: name}
+	n.Configuration.FenceMode = fenceMode
+
+	if opts != nil {
+		n.Description = opts.Description
+		n.IsShared = opts.Shared
+	}
+
+	return n, nil
+}
+
+func validateRoutedOptions(name string, opts *NetworkOptions) error {
+	if opts != nil && (opts.Distributed || opts.SubInterface) {
+		return fmt.Errorf("network %s: distributed and subinterface options only apply to routed networks", name)
+	}
+	return nil
+}
+
 func (d *Datacenter) findLinks(xt string) []t.Link {
 	var links []t.Link
 	for _, link := range d.Links {
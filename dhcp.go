@@ -0,0 +1,141 @@
+package vcloud
+
+import (
+	"fmt"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+const (
+	defaultDhcpLeaseTime    = 3600
+	defaultDhcpMaxLeaseTime = 7200
+)
+
+// DhcpPools ...
+func (g *EdgeGateway) DhcpPools() []t.DhcpPool {
+	dhcp := g.services().GatewayDhcpService
+	if dhcp == nil {
+		return nil
+	}
+	return dhcp.Pools
+}
+
+// GetDhcpPool returns the pool for the named network, or nil if the network
+// has no pool.
+func (g *EdgeGateway) GetDhcpPool(network string) *t.DhcpPool {
+	pools := g.DhcpPools()
+	for i := range pools {
+		if pools[i].Network.Name == network {
+			return &pools[i]
+		}
+	}
+	return nil
+}
+
+// SetDhcpPool creates or replaces the pool for the given network. The pool's
+// range must sit inside the network's subnet and must not overlap its
+// static ip ranges.
+func (g *EdgeGateway) SetDhcpPool(n *Network, pool t.DhcpPool) (*Task, error) {
+	if pool.MaxLeaseTime == 0 {
+		pool.MaxLeaseTime = defaultDhcpMaxLeaseTime
+	}
+
+	if pool.DefaultLeaseTime == 0 {
+		pool.DefaultLeaseTime = defaultDhcpLeaseTime
+	}
+
+	err := validateDhcpPool(n, &pool)
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Network = t.Reference{
+		Href: n.getAdminHref(),
+		Type: adminNetworkType,
+		Name: n.Name,
+	}
+
+	return g.updateService(dhcpService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		dhcp := services.GatewayDhcpService
+		for i := range dhcp.Pools {
+			if dhcp.Pools[i].Network.Name == n.Name {
+				dhcp.Pools[i] = pool
+				return nil
+			}
+		}
+		dhcp.Pools = append(dhcp.Pools, pool)
+		return nil
+	})
+}
+
+// RemoveDhcpPool ...
+func (g *EdgeGateway) RemoveDhcpPool(network string) (*Task, error) {
+	return g.updateService(dhcpService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		dhcp := services.GatewayDhcpService
+		for i := range dhcp.Pools {
+			if dhcp.Pools[i].Network.Name == network {
+				dhcp.Pools = append(dhcp.Pools[:i], dhcp.Pools[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("could not find dhcp pool for network %s on edge gateway %s", network, g.Name)
+	})
+}
+
+// SetDhcpEnabled ...
+func (g *EdgeGateway) SetDhcpEnabled(enabled bool) (*Task, error) {
+	return g.updateService(dhcpService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		dhcp := services.GatewayDhcpService
+		dhcp.IsEnabled = enabled
+		return nil
+	})
+}
+
+// dhcpService selects the dhcp service for updateService.
+func dhcpService(dst, src *t.EdgeGatewayServiceConfiguration) {
+	dhcp := t.GatewayDhcpService{IsEnabled: true}
+	if current := src.GatewayDhcpService; current != nil {
+		dhcp = *current
+		dhcp.Pools = append([]t.DhcpPool(nil), current.Pools...)
+	}
+	dst.GatewayDhcpService = &dhcp
+}
+
+func validateDhcpPool(n *Network, pool *t.DhcpPool) error {
+	low, high, err := parseIPRange(pool.LowIPAddress, pool.HighIPAddress)
+	if err != nil {
+		return err
+	}
+
+	subnet, err := parseSubnet(n.Gateway(), n.Netmask())
+	if err != nil {
+		return fmt.Errorf("network %s: %s", n.Name, err)
+	}
+
+	if !subnet.Contains(low) || !subnet.Contains(high) {
+		return fmt.Errorf("dhcp range %s-%s is outside of network %s (%s)", pool.LowIPAddress, pool.HighIPAddress, n.Name, subnet)
+	}
+
+	gw, _ := parseIP(n.Gateway())
+	if ipInRange(gw, low, high) {
+		return fmt.Errorf("dhcp range %s-%s includes the gateway address %s", pool.LowIPAddress, pool.HighIPAddress, n.Gateway())
+	}
+
+	for _, scope := range n.IPScopes() {
+		for _, r := range scope.IPRanges.IPRange {
+			start, end, err := parseIPRange(r.StartAddress, r.EndAddress)
+			if err != nil {
+				continue
+			}
+			if ipRangesOverlap(low, high, start, end) {
+				return fmt.Errorf("dhcp range %s-%s overlaps static ip range %s-%s", pool.LowIPAddress, pool.HighIPAddress, r.StartAddress, r.EndAddress)
+			}
+		}
+	}
+
+	if pool.MaxLeaseTime > 0 && pool.DefaultLeaseTime > pool.MaxLeaseTime {
+		return fmt.Errorf("dhcp default lease time %d exceeds the max lease time %d", pool.DefaultLeaseTime, pool.MaxLeaseTime)
+	}
+
+	return nil
+}
@@ -2,37 +2,84 @@ package vcloud
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"log"
+	"strings"
 
 	t "git.r3labs.io/libraries/go-vcloud/types"
 )
 
 const (
-	edgeGatewayType = "application/vnd.vmware.admin.edgeGateway+xml"
+	edgeGatewayType                     = "application/vnd.vmware.admin.edgeGateway+xml"
+	edgeGatewayServiceConfigurationType = "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml"
+	edgeGatewayFormFactorType           = "application/vnd.vmware.vcloud.edgeGatewayFormFactor+xml"
+	edgeGatewayURNPrefix                = "urn:vcloud:gateway:"
 )
 
 // EdgeGateway ...
 type EdgeGateway struct {
 	Connector     *Connector `xml:"-"`
-	XMLName       xml.Name   `xml:"EdgeGateway"`
+	XMLName       xml.Name   `xml:"http://www.vmware.com/vcloud/v1.5 EdgeGateway"`
+	ID            string     `xml:"id,attr,omitempty"`
 	Name          string     `xml:"name,attr"`
 	Href          string     `xml:"href,attr"`
+	Links         []t.Link   `xml:"Link"`
+	Description   string     `xml:"Description,omitempty"`
 	Configuration t.GatewayConfiguration
-	Links         []t.Link `xml:"Link"`
 }
 
-// FindEdgeGateway ...
+// FindEdgeGateway returns the named edge gateway in a vdc, or a not found
+// error if there is no such gateway.
 func FindEdgeGateway(c *Connector, dcHref string, name string) (*EdgeGateway, error) {
+	records, err := ListEdgeGateways(c, dcHref)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gwr := range records {
+		if gwr.Name == name {
+			return NewEdgeGateway(c, gwr.Href)
+		}
+	}
+
+	return nil, newNotFoundError(fmt.Sprintf("edge gateway %s not found", name))
+}
+
+// ListEdgeGateways returns records for all edge gateways in a vdc.
+func ListEdgeGateways(c *Connector, dcHref string) ([]t.EdgeGatewayRecord, error) {
 	q := Query{
 		Connector: c,
 		Type:      "edgeGateway",
 		Format:    "records",
-		Filter:    "vdc",
-		FilterArg: dcHref,
+		Filter:    FilterEquals("vdc", dcHref),
+	}
+
+	results, err := q.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return results.EdgeGatewayRecords, nil
+}
+
+// GetEdgeGatewayByID returns an edge gateway by its id, which can either be
+// a uuid or a urn such as urn:vcloud:gateway:<uuid>.
+func GetEdgeGatewayByID(c *Connector, id string) (*EdgeGateway, error) {
+	uuid := strings.TrimPrefix(id, edgeGatewayURNPrefix)
+	if uuid == "" || strings.Contains(uuid, ":") || strings.Contains(uuid, "/") {
+		return nil, fmt.Errorf("invalid edge gateway id %s", id)
+	}
+
+	return NewEdgeGateway(c, fmt.Sprintf("https://%s/api/admin/edgeGateway/%s", c.Config.URL, uuid))
+}
+
+// NewEdgeGateway ...
+func NewEdgeGateway(c *Connector, href string) (*EdgeGateway, error) {
+	if href == "" {
+		return nil, errors.New("edge gateway href must not be empty")
 	}
 
-	resp, err := q.Run()
+	resp, err := c.Get(href)
 	if err != nil {
 		return nil, err
 	}
@@ -42,44 +89,134 @@ func FindEdgeGateway(c *Connector, dcHref string, name string) (*EdgeGateway, er
 		return nil, err
 	}
 
-	results := t.QueryResultRecords{}
-	err = xml.Unmarshal(*data, &results)
+	gw := EdgeGateway{}
+	err = xml.Unmarshal(*data, &gw)
 	if err != nil {
 		return nil, err
 	}
 
-	gwHref := ""
+	gw.Connector = c
 
-	for _, gwr := range results.EdgeGatewayRecords {
-		if gwr.Name == name {
-			gwHref = gwr.Href
+	return &gw, nil
+}
+
+// Reload refreshes the gateway's configuration and services.
+func (g *EdgeGateway) Reload() error {
+	gw, err := NewEdgeGateway(g.Connector, g.Href)
+	if err != nil {
+		return err
+	}
+
+	*g = *gw
+
+	return nil
+}
+
+// gatewayInterface returns the gateway's interface on the named network.
+func (g *EdgeGateway) gatewayInterface(network string) (*t.GatewayInterface, error) {
+	interfaces := g.Configuration.GatewayInterfaces.Interfaces
+	for i := range interfaces {
+		if interfaces[i].Network.Name == network {
+			return &interfaces[i], nil
 		}
 	}
+	return nil, fmt.Errorf("edge gateway %s has no interface on network %s", g.Name, network)
+}
 
-	gw := NewEdgeGateway(c, gwHref)
+func (g *EdgeGateway) services() *t.EdgeGatewayServiceConfiguration {
+	if g.Configuration.EdgeGatewayServiceConfiguration == nil {
+		g.Configuration.EdgeGatewayServiceConfiguration = &t.EdgeGatewayServiceConfiguration{}
+	}
+	return g.Configuration.EdgeGatewayServiceConfiguration
+}
+
+// configureServices submits the given service configuration. Only the
+// services that are set are changed on the gateway.
+func (g *EdgeGateway) configureServices(config *t.EdgeGatewayServiceConfiguration) (*Task, error) {
+	href := findActionLink(g.Links, "action/configureServices")
+	if href == "" {
+		return nil, fmt.Errorf("edge gateway %s does not support configuring services", g.Name)
+	}
+
+	data, err := xml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
 
-	return gw, nil
+	return g.Connector.PostTask(href, data, edgeGatewayServiceConfigurationType)
 }
 
-// NewEdgeGateway ...
-func NewEdgeGateway(c *Connector, href string) *EdgeGateway {
-	resp, err := c.Get(href)
+// updateService applies fn to a copy of the service chosen by selector and
+// submits it, only updating the gateway once the change has been accepted.
+func (g *EdgeGateway) updateService(selector func(dst, src *t.EdgeGatewayServiceConfiguration), fn func(services *t.EdgeGatewayServiceConfiguration) error) (*Task, error) {
+	services := t.EdgeGatewayServiceConfiguration{}
+	selector(&services, g.services())
+
+	err := fn(&services)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 
-	data, err := ParseResponse(resp)
+	task, err := g.configureServices(&services)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 
-	gw := EdgeGateway{}
-	err = xml.Unmarshal(*data, &gw)
+	selector(g.services(), &services)
+
+	return task, nil
+}
+
+// update applies fn to a copy of the gateway's configuration and submits the
+// whole gateway, only updating it once the change has been accepted.
+func (g *EdgeGateway) update(fn func(config *t.GatewayConfiguration) error) (*Task, error) {
+	config := g.Configuration
+
+	current := g.Configuration.GatewayInterfaces.Interfaces
+	config.GatewayInterfaces.Interfaces = make([]t.GatewayInterface, len(current))
+	for i, iface := range current {
+		config.GatewayInterfaces.Interfaces[i] = iface
+		if r := iface.SubnetParticipation.IPRanges; r != nil {
+			config.GatewayInterfaces.Interfaces[i].SubnetParticipation.IPRanges = &t.IPRanges{
+				XMLName: r.XMLName,
+				IPRange: append([]t.IPRange(nil), r.IPRange...),
+			}
+		}
+	}
+
+	err := fn(&config)
 	if err != nil {
-		log.Println(err)
+		return nil, err
 	}
 
-	gw.Connector = c
+	gw := *g
+	gw.Configuration = config
+
+	href := findLinkByRel(g.Links, "edit")
+	if href == "" {
+		href = g.Href
+	}
+
+	data, err := xml.Marshal(gw)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := g.Connector.PutTask(href, data, edgeGatewayType)
+	if err != nil {
+		return nil, err
+	}
 
-	return &gw
+	g.Configuration = config
+
+	return task, nil
+}
+
+// action posts to one of the gateway's action links.
+func (g *EdgeGateway) action(action string, data []byte, contentType string) (*Task, error) {
+	href := findActionLink(g.Links, action)
+	if href == "" {
+		return nil, fmt.Errorf("edge gateway %s does not support action %s", g.Name, action)
+	}
+	return g.Connector.PostTask(href, data, contentType)
 }
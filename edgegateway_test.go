@@ -0,0 +1,596 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	types "git.r3labs.io/libraries/go-vcloud/types"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const edgeGatewayID = "5b8a2ee0-8f4c-4bd4-93e3-1b1e6d1c2a7f"
+
+func edgeGatewayHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	if ps.ByName("id") != edgeGatewayID {
+		notFoundHandler(w, r)
+		return
+	}
+
+	data, _ := loadFixture("fixtures/edgegateway.xml")
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(data)
+}
+
+func loadEdgeGateway(c *Connector) *EdgeGateway {
+	data, _ := loadFixture("fixtures/edgegateway.xml")
+	gw := EdgeGateway{}
+	_ = xml.Unmarshal(data, &gw)
+	gw.Connector = c
+	return &gw
+}
+
+// serveEdgeGateway loads the fixture gateway with its links pointing at the
+// test server.
+func serveEdgeGateway(c *Connector, host string) *EdgeGateway {
+	gw := loadEdgeGateway(c)
+	for i := range gw.Links {
+		gw.Links[i].Href = strings.Replace(gw.Links[i].Href, "vcloud.example.com", host, 1)
+	}
+	return gw
+}
+
+// submittedServices returns the service configuration last posted to the
+// test server.
+func submittedServices() (capturedRequest, *types.EdgeGatewayServiceConfiguration) {
+	req := lastRequest()
+	config := types.EdgeGatewayServiceConfiguration{}
+	_ = xml.Unmarshal(req.Body, &config)
+	return req, &config
+}
+
+func ruleIDs(rules []types.FirewallRule) []string {
+	var ids []string
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+func TestEdgeGatewayLookup(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given an authenticated connector", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+		dcHref := fmt.Sprintf("https://%s/api/vdc/7a1e40a6-9bc3-4d1c-b0e1-1d3c7c9b5e21", tsurl.Host)
+
+		Convey("When listing the gateways in a vdc", func() {
+			records, err := ListEdgeGateways(c, dcHref)
+			Convey("Typed records should be returned", func() {
+				So(err, ShouldBeNil)
+				So(records, ShouldHaveLength, 1)
+				So(records[0].Name, ShouldEqual, "gateway")
+				So(records[0].GatewayStatus, ShouldEqual, "READY")
+			})
+		})
+
+		Convey("When finding a gateway by name", func() {
+			gw, err := FindEdgeGateway(c, dcHref, "gateway")
+			Convey("The gateway should be loaded", func() {
+				So(err, ShouldBeNil)
+				So(gw.Name, ShouldEqual, "gateway")
+				So(gw.NatRules(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When finding a gateway that does not exist", func() {
+			gw, err := FindEdgeGateway(c, dcHref, "missing")
+			Convey("A not found error should be returned", func() {
+				So(gw, ShouldBeNil)
+				So(IsNotFound(err), ShouldBeTrue)
+			})
+		})
+
+		Convey("When getting a gateway by urn", func() {
+			gw, err := GetEdgeGatewayByID(c, "urn:vcloud:gateway:"+edgeGatewayID)
+			Convey("The gateway should be loaded", func() {
+				So(err, ShouldBeNil)
+				So(gw.ID, ShouldEqual, "urn:vcloud:gateway:"+edgeGatewayID)
+			})
+		})
+
+		Convey("When getting a gateway by an unknown id", func() {
+			_, err := GetEdgeGatewayByID(c, "00000000-0000-0000-0000-000000000000")
+			Convey("A not found error should be returned", func() {
+				So(IsNotFound(err), ShouldBeTrue)
+			})
+		})
+
+		Convey("When getting a gateway with an empty href", func() {
+			_, err := NewEdgeGateway(c, "")
+			Convey("An error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestEdgeGatewayFirewall(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given an edge gateway with a firewall rule", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+		gw := serveEdgeGateway(c, tsurl.Host)
+
+		rule := func(id string) types.FirewallRule {
+			return types.FirewallRule{
+				ID:                   id,
+				IsEnabled:            true,
+				Description:          "rule " + id,
+				Policy:               FirewallPolicyAllow,
+				Protocols:            &types.FirewallRuleProtocols{TCP: true},
+				DestinationPortRange: "Any",
+				DestinationIP:        "Any",
+				SourcePortRange:      "Any",
+				SourceIP:             "10.0.0.0/24",
+			}
+		}
+
+		Convey("The rule should be listed", func() {
+			So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"1"})
+		})
+
+		Convey("When adding a rule", func() {
+			task, err := gw.AddFirewallRule(rule("2"))
+			req, config := submittedServices()
+
+			Convey("The services should be posted to configureServices", func() {
+				So(err, ShouldBeNil)
+				So(task.Connector, ShouldEqual, c)
+				So(req.Method, ShouldEqual, "POST")
+				So(req.Path, ShouldEqual, "/api/admin/edgeGateway/"+edgeGatewayID+"/action/configureServices")
+				So(req.ContentType, ShouldEqual, edgeGatewayServiceConfigurationType)
+				So(string(req.Body), ShouldStartWith, `<EdgeGatewayServiceConfiguration xmlns="http://www.vmware.com/vcloud/v1.5">`)
+			})
+			Convey("Only the firewall service should be submitted", func() {
+				So(config.FirewallService, ShouldNotBeNil)
+				So(config.NatService, ShouldBeNil)
+				So(config.GatewayDhcpService, ShouldBeNil)
+				So(config.FirewallService.DefaultAction, ShouldEqual, FirewallPolicyDrop)
+			})
+			Convey("The rule should be appended after the existing rule", func() {
+				So(ruleIDs(config.FirewallService.FirewallRules), ShouldResemble, []string{"1", "2"})
+				So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"1", "2"})
+			})
+
+			Convey("When moving it to the top", func() {
+				_, err := gw.MoveFirewallRule("2", 0)
+				_, config := submittedServices()
+				Convey("The rules should be reordered", func() {
+					So(err, ShouldBeNil)
+					So(ruleIDs(config.FirewallService.FirewallRules), ShouldResemble, []string{"2", "1"})
+					So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"2", "1"})
+				})
+			})
+
+			Convey("When moving it out of range", func() {
+				_, err := gw.MoveFirewallRule("2", 2)
+				So(err, ShouldNotBeNil)
+				_, err = gw.MoveFirewallRule("2", -1)
+				So(err, ShouldNotBeNil)
+				So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"1", "2"})
+			})
+
+			Convey("When removing the first rule", func() {
+				_, err := gw.RemoveFirewallRule("1")
+				_, config := submittedServices()
+				Convey("Only the new rule should remain", func() {
+					So(err, ShouldBeNil)
+					So(ruleIDs(config.FirewallService.FirewallRules), ShouldResemble, []string{"2"})
+					So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"2"})
+				})
+			})
+		})
+
+		Convey("When adding an invalid rule", func() {
+			invalid := rule("2")
+			invalid.Policy = "reject"
+			task, err := gw.AddFirewallRule(invalid)
+			So(err, ShouldNotBeNil)
+			So(task, ShouldBeNil)
+			So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"1"})
+		})
+
+		Convey("When moving or removing an unknown rule", func() {
+			_, err := gw.MoveFirewallRule("9", 0)
+			So(err, ShouldNotBeNil)
+			_, err = gw.RemoveFirewallRule("9")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When replacing the rules", func() {
+			rules := []types.FirewallRule{rule("3"), rule("4"), rule("5")}
+			_, err := gw.SetFirewallRules(rules)
+			_, config := submittedServices()
+			rules[0].ID = "changed"
+			Convey("The rules should be submitted in order", func() {
+				So(err, ShouldBeNil)
+				So(ruleIDs(config.FirewallService.FirewallRules), ShouldResemble, []string{"3", "4", "5"})
+			})
+			Convey("The gateway should keep its own copy", func() {
+				So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"3", "4", "5"})
+			})
+		})
+
+		Convey("When changing the default action", func() {
+			_, err := gw.SetFirewallDefaultAction(FirewallPolicyAllow, true)
+			_, config := submittedServices()
+			Convey("The default action should be submitted with the existing rules", func() {
+				So(err, ShouldBeNil)
+				So(config.FirewallService.DefaultAction, ShouldEqual, FirewallPolicyAllow)
+				So(config.FirewallService.LogDefaultAction, ShouldBeTrue)
+				So(ruleIDs(config.FirewallService.FirewallRules), ShouldResemble, []string{"1"})
+			})
+			Convey("An unknown default action should be rejected", func() {
+				_, err := gw.SetFirewallDefaultAction("reject", false)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the gateway cannot configure services", func() {
+			gw.Links = nil
+			task, err := gw.AddFirewallRule(rule("2"))
+			Convey("The gateway should be left unchanged", func() {
+				So(err, ShouldNotBeNil)
+				So(task, ShouldBeNil)
+				So(ruleIDs(gw.FirewallRules()), ShouldResemble, []string{"1"})
+			})
+		})
+	})
+}
+
+func TestEdgeGatewayNat(t *testing.T) {
+	Convey("Given an edge gateway with nat rules", t, func() {
+		gw := loadEdgeGateway(NewConnector(&Config{URL: "vcloud.example.com"}))
+
+		Convey("The rules should be split by type", func() {
+			So(gw.NatRules(), ShouldHaveLength, 2)
+			So(gw.DNATRules(), ShouldHaveLength, 1)
+			So(gw.SNATRules(), ShouldHaveLength, 1)
+		})
+
+		Convey("When ensuring an identical DNAT rule exists", func() {
+			task, err := gw.EnsureDNAT("external", "203.0.113.10", "443", "10.0.0.10", "443", "TCP")
+			Convey("Nothing should be changed", func() {
+				So(err, ShouldBeNil)
+				So(task, ShouldBeNil)
+				So(gw.NatRules(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When ensuring an identical SNAT rule exists", func() {
+			task, err := gw.EnsureSNAT("external", "10.0.0.0/24", "203.0.113.11")
+			Convey("Nothing should be changed", func() {
+				So(err, ShouldBeNil)
+				So(task, ShouldBeNil)
+			})
+		})
+
+		Convey("When adding a rule on an unknown network", func() {
+			task, err := gw.AddDNAT("missing", "203.0.113.12", "80", "10.0.0.11", "80", "tcp")
+			Convey("An error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(task, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestEdgeGatewaySNAT(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given an edge gateway with nat rules", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+		gw := serveEdgeGateway(c, tsurl.Host)
+
+		Convey("When adding an SNAT rule", func() {
+			_, err := gw.AddSNAT("external", "10.0.1.0/24", "203.0.113.12")
+			req, config := submittedServices()
+			So(err, ShouldBeNil)
+
+			Convey("It should be appended to the existing rules", func() {
+				So(config.NatService.NatRules, ShouldHaveLength, 3)
+				rule := config.NatService.NatRules[2]
+				So(rule.RuleType, ShouldEqual, NatRuleTypeSNAT)
+				So(rule.GatewayNatRule.OriginalIP, ShouldEqual, "10.0.1.0/24")
+				So(rule.GatewayNatRule.TranslatedIP, ShouldEqual, "203.0.113.12")
+				So(rule.GatewayNatRule.Interface.Name, ShouldEqual, "external")
+			})
+			Convey("Its ports and protocol should not be marshalled", func() {
+				body := string(req.Body)
+				rule := body[strings.LastIndex(body, "<NatRule>"):]
+				So(rule, ShouldContainSubstring, "<OriginalIp>10.0.1.0/24</OriginalIp>")
+				So(rule, ShouldNotContainSubstring, "Port>")
+				So(rule, ShouldNotContainSubstring, "<Protocol>")
+			})
+		})
+
+		Convey("When ensuring a new SNAT rule", func() {
+			_, err := gw.EnsureSNAT("external", "10.0.1.0/24", "203.0.113.12")
+			_, config := submittedServices()
+			Convey("Its ports and protocol should be left empty", func() {
+				So(err, ShouldBeNil)
+				rule := config.NatService.NatRules[2].GatewayNatRule
+				So(rule.OriginalPort, ShouldBeEmpty)
+				So(rule.TranslatedPort, ShouldBeEmpty)
+				So(rule.Protocol, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestEdgeGatewayDhcpValidation(t *testing.T) {
+	Convey("Given a network with a static ip range", t, func() {
+		n := &Network{Name: "internal"}
+		n.SetGateway("10.0.0.1")
+		n.SetNetmask("255.255.255.0")
+		n.SetStartAddress("10.0.0.10")
+		n.SetEndAddress("10.0.0.99")
+
+		Convey("A range inside the subnet and outside the static range should be valid", func() {
+			pool := types.DhcpPool{LowIPAddress: "10.0.0.100", HighIPAddress: "10.0.0.200"}
+			So(validateDhcpPool(n, &pool), ShouldBeNil)
+		})
+
+		Convey("A range outside the subnet should be rejected", func() {
+			pool := types.DhcpPool{LowIPAddress: "10.0.1.100", HighIPAddress: "10.0.1.200"}
+			So(validateDhcpPool(n, &pool), ShouldNotBeNil)
+		})
+
+		Convey("A range overlapping the static range should be rejected", func() {
+			pool := types.DhcpPool{LowIPAddress: "10.0.0.50", HighIPAddress: "10.0.0.150"}
+			So(validateDhcpPool(n, &pool), ShouldNotBeNil)
+		})
+
+		Convey("A range including the gateway should be rejected", func() {
+			pool := types.DhcpPool{LowIPAddress: "10.0.0.1", HighIPAddress: "10.0.0.5"}
+			So(validateDhcpPool(n, &pool), ShouldNotBeNil)
+		})
+
+		Convey("A reversed range should be rejected", func() {
+			pool := types.DhcpPool{LowIPAddress: "10.0.0.200", HighIPAddress: "10.0.0.100"}
+			So(validateDhcpPool(n, &pool), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestEdgeGatewayStaticRouteValidation(t *testing.T) {
+	Convey("Given an edge gateway interface", t, func() {
+		gw := loadEdgeGateway(NewConnector(&Config{URL: "vcloud.example.com"}))
+		iface, err := gw.gatewayInterface("internal")
+		So(err, ShouldBeNil)
+
+		Convey("A reachable next hop should be valid", func() {
+			So(validateStaticRoute(iface, "192.168.0.0/24", "10.0.0.254"), ShouldBeNil)
+		})
+
+		Convey("An invalid cidr should be rejected", func() {
+			So(validateStaticRoute(iface, "192.168.0.0", "10.0.0.254"), ShouldNotBeNil)
+		})
+
+		Convey("An unreachable next hop should be rejected", func() {
+			So(validateStaticRoute(iface, "192.168.0.0/24", "172.16.0.1"), ShouldNotBeNil)
+		})
+
+		Convey("The interface's own address should be rejected", func() {
+			So(validateStaticRoute(iface, "192.168.0.0/24", "10.0.0.1"), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestEdgeGatewayIpsecVpn(t *testing.T) {
+	Convey("Given an edge gateway without vpn endpoints", t, func() {
+		gw := loadEdgeGateway(NewConnector(&Config{URL: "vcloud.example.com"}))
+
+		tunnel := types.GatewayIpsecVpnTunnel{
+			Name:               "site-b",
+			PeerIPAddress:      "198.51.100.1",
+			LocalIPAddress:     "203.0.113.12",
+			LocalSubnets:       []types.IpsecVpnSubnet{{Name: "internal", Gateway: "10.0.0.1", Netmask: "255.255.255.0"}},
+			PeerSubnets:        []types.IpsecVpnSubnet{{Name: "remote", Gateway: "10.1.0.1", Netmask: "255.255.255.0"}},
+			SharedSecret:       "secret",
+			EncryptionProtocol: IpsecEncryptionAES256,
+		}
+
+		Convey("A complete tunnel should be valid and defaulted", func() {
+			So(validateIpsecVpnTunnel(&tunnel), ShouldBeNil)
+			So(tunnel.PeerID, ShouldEqual, "198.51.100.1")
+			So(tunnel.LocalID, ShouldEqual, "203.0.113.12")
+			So(tunnel.Mtu, ShouldEqual, 1500)
+		})
+
+		Convey("A tunnel without a shared secret should be rejected", func() {
+			tunnel.SharedSecret = ""
+			So(validateIpsecVpnTunnel(&tunnel), ShouldNotBeNil)
+		})
+
+		Convey("A tunnel with an unknown encryption protocol should be rejected", func() {
+			tunnel.EncryptionProtocol = "DES"
+			So(validateIpsecVpnTunnel(&tunnel), ShouldNotBeNil)
+		})
+
+		Convey("Adding a tunnel should fail as its local ip is not an endpoint", func() {
+			task, err := gw.AddIpsecVpnTunnel(tunnel)
+			So(err, ShouldNotBeNil)
+			So(task, ShouldBeNil)
+			So(gw.IpsecVpnTunnels(), ShouldHaveLength, 0)
+		})
+	})
+}
+
+func TestEdgeGatewayLoadBalancer(t *testing.T) {
+	Convey("Given an edge gateway with a pool used by a virtual server", t, func() {
+		gw := loadEdgeGateway(NewConnector(&Config{URL: "vcloud.example.com"}))
+
+		pool := types.LoadBalancerPool{
+			Name: "web",
+			ServicePorts: []types.LoadBalancerPoolPort{
+				{IsEnabled: true, Protocol: "HTTP", Algorithm: "ROUND_ROBIN", Port: "80", HealthCheckPort: "80"},
+			},
+			Members: []types.LoadBalancerPoolMember{{IPAddress: "10.0.0.10", Weight: "1"}},
+		}
+
+		vs := types.LoadBalancerVirtualServer{
+			Name:      "www",
+			Interface: &types.Reference{Href: "https://vcloud.example.com/api/admin/network/0cd5a0e3-0a11-4a5b-9b39-2f3d0b1b6a11"},
+			IPAddress: "203.0.113.12",
+			Pool:      "web",
+			ServiceProfiles: []types.LoadBalancerServiceProfile{
+				{IsEnabled: true, Protocol: "HTTP", Port: "80"},
+			},
+		}
+
+		gw.services().LoadBalancerService = &types.LoadBalancerService{
+			IsEnabled:      true,
+			Pools:          []types.LoadBalancerPool{pool},
+			VirtualServers: []types.LoadBalancerVirtualServer{vs},
+		}
+
+		Convey("The pool and virtual server should be valid", func() {
+			So(validateLoadBalancerPool(&pool), ShouldBeNil)
+			So(validateLoadBalancerVirtualServer(&vs), ShouldBeNil)
+		})
+
+		Convey("A pool with an unknown algorithm should be rejected", func() {
+			pool.ServicePorts[0].Algorithm = "RANDOM"
+			So(validateLoadBalancerPool(&pool), ShouldNotBeNil)
+		})
+
+		Convey("Cookie persistence without a cookie name should be rejected", func() {
+			vs.ServiceProfiles[0].Persistence = &types.LoadBalancerPersistence{Method: "COOKIE"}
+			So(validateLoadBalancerVirtualServer(&vs), ShouldNotBeNil)
+		})
+
+		Convey("Removing the pool should fail while it is in use", func() {
+			task, err := gw.RemoveLoadBalancerPool("web")
+			So(err, ShouldNotBeNil)
+			So(task, ShouldBeNil)
+			So(gw.LoadBalancerPools(), ShouldHaveLength, 1)
+		})
+
+		Convey("Adding a virtual server on an internal interface should fail", func() {
+			vs.Name = "intranet"
+			task, err := gw.AddLoadBalancerVirtualServer("internal", vs)
+			So(err, ShouldNotBeNil)
+			So(task, ShouldBeNil)
+		})
+	})
+}
+
+func TestEdgeGatewaySubAllocations(t *testing.T) {
+	Convey("Given an edge gateway with a sub-allocated range", t, func() {
+		gw := loadEdgeGateway(NewConnector(&Config{URL: "vcloud.example.com"}))
+
+		Convey("The range should be listed against its uplink", func() {
+			allocations := gw.SubAllocations()
+			So(allocations, ShouldHaveLength, 1)
+			So(allocations["external"], ShouldHaveLength, 1)
+			So(allocations["external"][0].StartAddress, ShouldEqual, "203.0.113.10")
+		})
+
+		Convey("The next free ip should skip addresses used by the interface and nat rules", func() {
+			ip, err := gw.NextFreeIP("external")
+			So(err, ShouldBeNil)
+			So(ip, ShouldEqual, "203.0.113.12")
+		})
+
+		Convey("Internal networks should not have sub-allocations", func() {
+			_, err := gw.GetSubAllocations("internal")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Releasing a range that is still in use should fail", func() {
+			task, err := gw.ReleaseIPRange("external", "203.0.113.10", "203.0.113.14")
+			So(err, ShouldNotBeNil)
+			So(task, ShouldBeNil)
+		})
+
+		Convey("When validating a new range", func() {
+			iface, _ := gw.gatewayInterface("external")
+
+			Convey("A free range inside the subnet should be valid", func() {
+				So(validateSubAllocation(iface, "203.0.113.20", "203.0.113.29"), ShouldBeNil)
+			})
+
+			Convey("An overlapping range should be rejected", func() {
+				So(validateSubAllocation(iface, "203.0.113.14", "203.0.113.20"), ShouldNotBeNil)
+			})
+
+			Convey("A range outside the subnet should be rejected", func() {
+				So(validateSubAllocation(iface, "198.51.100.10", "198.51.100.20"), ShouldNotBeNil)
+			})
+
+			Convey("A range including the gateway should be rejected", func() {
+				So(validateSubAllocation(iface, "203.0.113.1", "203.0.113.5"), ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestEdgeGatewayActions(t *testing.T) {
+	Convey("Given an edge gateway without advanced networking links", t, func() {
+		gw := loadEdgeGateway(NewConnector(&Config{URL: "vcloud.example.com"}))
+
+		Convey("An invalid form factor should be rejected", func() {
+			_, err := gw.ModifyFormFactor("huge")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Actions without a link should fail", func() {
+			_, err := gw.ConvertToAdvanced()
+			So(err, ShouldNotBeNil)
+			_, err = gw.EnableDistributedRouting()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Advanced networking should be reported as disabled", func() {
+			So(gw.AdvancedNetworkingEnabled(), ShouldBeFalse)
+			So(gw.DistributedRoutingEnabled(), ShouldBeFalse)
+		})
+	})
+}
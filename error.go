@@ -2,11 +2,39 @@ package vcloud
 
 import (
 	"encoding/xml"
+	"errors"
 	"log"
+	"net/http"
+	"strconv"
 
 	t "git.r3labs.io/libraries/go-vcloud/types"
 )
 
+// Minor error codes
+const (
+	ErrorCodeResourceNotFound = "RESOURCE_NOT_FOUND"
+	ErrorCodeUnauthorized     = "UNAUTHORIZED"
+	ErrorCodeAccessForbidden  = "ACCESS_TO_RESOURCE_IS_FORBIDDEN"
+	ErrorCodeBusyEntity       = "BUSY_ENTITY"
+	ErrorCodeBadRequest       = "BAD_REQUEST"
+	ErrorCodeDuplicateName    = "DUPLICATE_NAME"
+)
+
+// Error is returned when vCloud responds with an error document.
+type Error struct {
+	StatusCode              int
+	MajorErrorCode          int
+	MinorErrorCode          string
+	VendorSpecificErrorCode string
+	Message                 string
+	RequestID               string
+	StackTrace              string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
 // ParseError ...
 func ParseError(d *[]byte) *t.Error {
 	e := t.Error{}
@@ -16,3 +44,74 @@ func ParseError(d *[]byte) *t.Error {
 	}
 	return &e
 }
+
+// IsNotFound ...
+func IsNotFound(err error) bool {
+	return hasError(err, http.StatusNotFound, ErrorCodeResourceNotFound)
+}
+
+// IsUnauthorized ...
+func IsUnauthorized(err error) bool {
+	return hasError(err, http.StatusUnauthorized, ErrorCodeUnauthorized)
+}
+
+// IsAccessForbidden ...
+func IsAccessForbidden(err error) bool {
+	return hasError(err, http.StatusForbidden, ErrorCodeAccessForbidden)
+}
+
+// IsBusyEntity ...
+func IsBusyEntity(err error) bool {
+	return hasError(err, 0, ErrorCodeBusyEntity)
+}
+
+func hasError(err error, status int, code string) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	return (status != 0 && e.StatusCode == status) || e.MinorErrorCode == code
+}
+
+func newError(resp *http.Response) error {
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	// responses that are not an error document, such as those from a load
+	// balancer, will leave the parsed error empty
+	vcloudErr := t.Error{}
+	_ = xml.Unmarshal(*data, &vcloudErr)
+
+	e := errorFromType(&vcloudErr)
+	e.StatusCode = resp.StatusCode
+	e.RequestID = resp.Header.Get("X-VMWARE-VCLOUD-REQUEST-ID")
+
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return e
+}
+
+func newNotFoundError(message string) *Error {
+	return &Error{
+		StatusCode:     http.StatusNotFound,
+		MajorErrorCode: http.StatusNotFound,
+		MinorErrorCode: ErrorCodeResourceNotFound,
+		Message:        message,
+	}
+}
+
+func errorFromType(vcloudErr *t.Error) *Error {
+	major, _ := strconv.Atoi(vcloudErr.MajorErrorCode)
+	return &Error{
+		StatusCode:              major,
+		MajorErrorCode:          major,
+		MinorErrorCode:          vcloudErr.MinorErrorCode,
+		VendorSpecificErrorCode: vcloudErr.VendorSpecificErrorCode,
+		Message:                 vcloudErr.Message,
+		StackTrace:              vcloudErr.StackTrace,
+	}
+}
@@ -0,0 +1,314 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+const (
+	extensionNamespace       = "http://www.vmware.com/vcloud/extension/v1.5"
+	vimObjectTypePortGroup   = "NETWORK"
+	vimObjectTypeDVPortGroup = "DV_PORTGROUP"
+)
+
+// ExternalNetwork is a provider network backed by a vCenter port group,
+// available to system administrators through the extension api.
+type ExternalNetwork struct {
+	Connector     *Connector `xml:"-"`
+	XMLName       xml.Name   `xml:"http://www.vmware.com/vcloud/extension/v1.5 VMWExternalNetwork"`
+	Type          string     `xml:"type,attr,omitempty"`
+	Name          string     `xml:"name,attr"`
+	Href          string     `xml:"href,attr,omitempty"`
+	ID            string     `xml:"id,attr,omitempty"`
+	Status        string     `xml:"status,attr,omitempty"`
+	Links         []t.Link   `xml:"http://www.vmware.com/vcloud/v1.5 Link"`
+	Description   string     `xml:"http://www.vmware.com/vcloud/v1.5 Description,omitempty"`
+	Tasks         *Tasks     `xml:"http://www.vmware.com/vcloud/v1.5 Tasks,omitempty"`
+	Configuration struct {
+		IPScopes  t.IPScopes `xml:"IpScopes"`
+		FenceMode string     `xml:"FenceMode"`
+	} `xml:"http://www.vmware.com/vcloud/v1.5 Configuration"`
+	VimPortGroupRef *t.VimObjectRef `xml:"VimPortGroupRef,omitempty"`
+}
+
+// IPUsage summarises how an external network's static ip ranges are used.
+type IPUsage struct {
+	Total        uint64
+	Allocated    []string
+	SubAllocated map[string][]t.IPRange
+	Free         uint64
+}
+
+// ListExternalNetworks ...
+func ListExternalNetworks(c *Connector) ([]t.Reference, error) {
+	resp, err := c.Get(extensionURL(c, "externalNetworkReferences"))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs t.VMWExternalNetworkReferences
+	err = xml.Unmarshal(*data, &refs)
+	if err != nil {
+		return nil, err
+	}
+
+	return refs.References, nil
+}
+
+// FindExternalNetwork returns the named external network, or a not found
+// error if there is no such network.
+func FindExternalNetwork(c *Connector, name string) (*ExternalNetwork, error) {
+	refs, err := ListExternalNetworks(c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if ref.Name == name {
+			return NewExternalNetwork(c, ref.Href)
+		}
+	}
+
+	return nil, newNotFoundError(fmt.Sprintf("external network %s not found", name))
+}
+
+// NewExternalNetwork ...
+func NewExternalNetwork(c *Connector, href string) (*ExternalNetwork, error) {
+	resp, err := c.Get(href)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	n := ExternalNetwork{}
+	err = xml.Unmarshal(*data, &n)
+	if err != nil {
+		return nil, err
+	}
+
+	n.Connector = c
+
+	return &n, nil
+}
+
+// CreateExternalNetwork creates an external network. The returned network's
+// tasks track its creation.
+func CreateExternalNetwork(c *Connector, n *ExternalNetwork) (*ExternalNetwork, error) {
+	if n.Configuration.FenceMode == "" {
+		n.Configuration.FenceMode = FenceModeIsolated
+	}
+
+	err := n.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := xml.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Post(extensionURL(c, "externalnets"), data, externalNetworkType)
+	if err != nil {
+		return nil, err
+	}
+
+	rdata, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	created := ExternalNetwork{}
+	err = xml.Unmarshal(*rdata, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Connector = c
+
+	return &created, nil
+}
+
+// Reload ...
+func (n *ExternalNetwork) Reload() error {
+	updated, err := NewExternalNetwork(n.Connector, n.Href)
+	if err != nil {
+		return err
+	}
+	*n = *updated
+	return nil
+}
+
+// Update ...
+func (n *ExternalNetwork) Update() (*Task, error) {
+	err := n.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := xml.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.Connector.PutTask(n.Href, data, externalNetworkType)
+}
+
+// Delete ...
+func (n *ExternalNetwork) Delete() (*Task, error) {
+	return n.Connector.DeleteTask(n.Href)
+}
+
+// GetTasks ...
+func (n *ExternalNetwork) GetTasks() []Task {
+	if n.Tasks == nil {
+		return nil
+	}
+	for i := 0; i < len(n.Tasks.Task); i++ {
+		n.Tasks.Task[i].Connector = n.Connector
+	}
+	return n.Tasks.Task
+}
+
+// IPUsage reports the addresses allocated from, and ranges sub-allocated to
+// edge gateways from, the network's static ip ranges as of its last reload.
+// Only allocated addresses inside the static ranges, and outside any
+// sub-allocation, count against the free addresses.
+func (n *ExternalNetwork) IPUsage() (*IPUsage, error) {
+	usage := IPUsage{SubAllocated: make(map[string][]t.IPRange)}
+	scopes := n.Configuration.IPScopes.IPScope
+
+	var static, subAllocated []ipRange
+
+	total := new(big.Int)
+	usedCount := new(big.Int)
+
+	for _, scope := range scopes {
+		for _, r := range scope.IPRanges.IPRange {
+			start, end, err := parseIPRange(r.StartAddress, r.EndAddress)
+			if err != nil {
+				return nil, err
+			}
+			static = append(static, ipRange{start, end})
+			total.Add(total, rangeSize(start, end))
+		}
+
+		if scope.SubAllocations == nil {
+			continue
+		}
+
+		for _, sa := range scope.SubAllocations.SubAllocation {
+			for _, r := range sa.IPRanges.IPRange {
+				start, end, err := parseIPRange(r.StartAddress, r.EndAddress)
+				if err != nil {
+					return nil, err
+				}
+				subAllocated = append(subAllocated, ipRange{start, end})
+				usedCount.Add(usedCount, rangeSize(start, end))
+				usage.SubAllocated[sa.EdgeGateway.Name] = append(usage.SubAllocated[sa.EdgeGateway.Name], r)
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+
+	for _, scope := range scopes {
+		if scope.AllocatedIPAddresses == nil {
+			continue
+		}
+
+		for _, address := range scope.AllocatedIPAddresses.IPAddress {
+			ip, err := parseIP(address)
+			if err != nil {
+				return nil, err
+			}
+
+			usage.Allocated = append(usage.Allocated, address)
+
+			if used[ip.String()] || !inIPRanges(ip, static) || inIPRanges(ip, subAllocated) {
+				continue
+			}
+			used[ip.String()] = true
+
+			usedCount.Add(usedCount, big.NewInt(1))
+		}
+	}
+
+	usage.Total = bigToUint64(total)
+	if total.Cmp(usedCount) > 0 {
+		usage.Free = bigToUint64(new(big.Int).Sub(total, usedCount))
+	}
+
+	return &usage, nil
+}
+
+func (n *ExternalNetwork) validate() error {
+	if n.Name == "" {
+		return fmt.Errorf("external network name must not be empty")
+	}
+
+	pg := n.VimPortGroupRef
+	if pg == nil || pg.MoRef == "" || pg.VimServerRef.Href == "" {
+		return fmt.Errorf("external network %s must reference a vcenter port group", n.Name)
+	}
+
+	if pg.VimObjectType != vimObjectTypePortGroup && pg.VimObjectType != vimObjectTypeDVPortGroup {
+		return fmt.Errorf("external network %s: invalid port group type %s", n.Name, pg.VimObjectType)
+	}
+
+	if len(n.Configuration.IPScopes.IPScope) < 1 {
+		return fmt.Errorf("external network %s must have an ip scope", n.Name)
+	}
+
+	err := validateIPScopes(n.Configuration.IPScopes.IPScope)
+	if err != nil {
+		return fmt.Errorf("external network %s: %s", n.Name, err)
+	}
+
+	return nil
+}
+
+func extensionURL(c *Connector, path string) string {
+	return fmt.Sprintf("https://%s/api/admin/extension/%s", c.Config.URL, path)
+}
+
+type ipRange struct {
+	start, end net.IP
+}
+
+func inIPRanges(ip net.IP, ranges []ipRange) bool {
+	for _, r := range ranges {
+		if ipInRange(ip, r.start, r.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeSize returns the number of addresses from start to end inclusive.
+func rangeSize(start, end net.IP) *big.Int {
+	s := new(big.Int).SetBytes(start.To16())
+	e := new(big.Int).SetBytes(end.To16())
+	return e.Sub(e, s).Add(e, big.NewInt(1))
+}
+
+func bigToUint64(i *big.Int) uint64 {
+	if !i.IsUint64() {
+		return math.MaxUint64
+	}
+	return i.Uint64()
+}
@@ -0,0 +1,215 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	types "git.r3labs.io/libraries/go-vcloud/types"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func externalNetworkReferencesHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<vmext:VMWExternalNetworkReferences xmlns:vmext="http://www.vmware.com/vcloud/extension/v1.5" xmlns:vcloud="http://www.vmware.com/vcloud/v1.5">
+    <vmext:ExternalNetworkReference type="application/vnd.vmware.admin.vmwexternalnet+xml" name="uplink" href="https://%s/api/admin/extension/externalnet/1"/>
+</vmext:VMWExternalNetworkReferences>`, r.Host)
+}
+
+func externalNetworkHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<vmext:VMWExternalNetwork xmlns:vmext="http://www.vmware.com/vcloud/extension/v1.5" xmlns:vcloud="http://www.vmware.com/vcloud/v1.5" name="uplink" type="application/vnd.vmware.admin.vmwexternalnet+xml" href="https://%s/api/admin/extension/externalnet/%s">
+    <vcloud:Description>public uplink</vcloud:Description>
+    <vcloud:Configuration>
+        <vcloud:IpScopes>
+            <vcloud:IpScope>
+                <vcloud:IsInherited>false</vcloud:IsInherited>
+                <vcloud:Gateway>203.0.113.1</vcloud:Gateway>
+                <vcloud:Netmask>255.255.255.0</vcloud:Netmask>
+                <vcloud:IsEnabled>true</vcloud:IsEnabled>
+                <vcloud:IpRanges>
+                    <vcloud:IpRange>
+                        <vcloud:StartAddress>203.0.113.10</vcloud:StartAddress>
+                        <vcloud:EndAddress>203.0.113.109</vcloud:EndAddress>
+                    </vcloud:IpRange>
+                </vcloud:IpRanges>
+                <vcloud:AllocatedIpAddresses>
+                    <vcloud:IpAddress>203.0.113.10</vcloud:IpAddress>
+                    <vcloud:IpAddress>203.0.113.50</vcloud:IpAddress>
+                </vcloud:AllocatedIpAddresses>
+                <vcloud:SubAllocations>
+                    <vcloud:SubAllocation>
+                        <vcloud:EdgeGateway type="application/vnd.vmware.admin.edgeGateway+xml" name="gateway" href="https://%s/api/admin/edgeGateway/1"/>
+                        <vcloud:IpRanges>
+                            <vcloud:IpRange>
+                                <vcloud:StartAddress>203.0.113.10</vcloud:StartAddress>
+                                <vcloud:EndAddress>203.0.113.14</vcloud:EndAddress>
+                            </vcloud:IpRange>
+                        </vcloud:IpRanges>
+                    </vcloud:SubAllocation>
+                </vcloud:SubAllocations>
+            </vcloud:IpScope>
+        </vcloud:IpScopes>
+        <vcloud:FenceMode>isolated</vcloud:FenceMode>
+    </vcloud:Configuration>
+    <vmext:VimPortGroupRef>
+        <vmext:VimServerRef type="application/vnd.vmware.admin.vmwvirtualcenter+xml" name="vc" href="https://%s/api/admin/extension/vimServer/1"/>
+        <vmext:MoRef>dvportgroup-42</vmext:MoRef>
+        <vmext:VimObjectType>DV_PORTGROUP</vmext:VimObjectType>
+    </vmext:VimPortGroupRef>
+</vmext:VMWExternalNetwork>`, r.Host, ps.ByName("id"), r.Host, r.Host)
+}
+
+func TestExternalNetworks(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a system administrator connector", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		Convey("When finding an external network", func() {
+			n, err := FindExternalNetwork(c, "uplink")
+			Convey("The network should be loaded", func() {
+				So(err, ShouldBeNil)
+				So(n.Description, ShouldEqual, "public uplink")
+				So(n.VimPortGroupRef.MoRef, ShouldEqual, "dvportgroup-42")
+				So(n.Configuration.FenceMode, ShouldEqual, FenceModeIsolated)
+			})
+
+			Convey("Its ip usage should be reported", func() {
+				usage, err := n.IPUsage()
+				So(err, ShouldBeNil)
+				So(usage.Total, ShouldEqual, 100)
+				So(usage.Allocated, ShouldHaveLength, 2)
+				So(usage.SubAllocated["gateway"], ShouldHaveLength, 1)
+				So(usage.Free, ShouldEqual, 94)
+			})
+
+			Convey("When updating it", func() {
+				n.Description = "public uplink 2"
+				task, err := n.Update()
+				req := lastRequest()
+
+				Convey("The network should be put to its href", func() {
+					So(err, ShouldBeNil)
+					So(task, ShouldNotBeNil)
+					So(req.Method, ShouldEqual, "PUT")
+					So(req.Path, ShouldEqual, "/api/admin/extension/externalnet/1")
+					So(req.ContentType, ShouldEqual, externalNetworkType)
+					var updated ExternalNetwork
+					So(xml.Unmarshal(req.Body, &updated), ShouldBeNil)
+					So(updated.Description, ShouldEqual, "public uplink 2")
+					So(updated.VimPortGroupRef.MoRef, ShouldEqual, "dvportgroup-42")
+				})
+			})
+
+			Convey("When deleting it", func() {
+				task, err := n.Delete()
+				req := lastRequest()
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+				So(req.Method, ShouldEqual, "DELETE")
+				So(req.Path, ShouldEqual, "/api/admin/extension/externalnet/1")
+			})
+
+			Convey("When reloading it", func() {
+				n.Description = "changed"
+				So(n.Reload(), ShouldBeNil)
+				So(n.Description, ShouldEqual, "public uplink")
+				So(n.Connector, ShouldEqual, c)
+			})
+		})
+
+		Convey("When an external network has several ip scopes", func() {
+			n := &ExternalNetwork{Name: "uplink-3"}
+			n.Configuration.IPScopes.IPScope = []types.IPScope{
+				{
+					IPRanges: types.IPRanges{IPRange: []types.IPRange{
+						{StartAddress: "198.51.100.10", EndAddress: "198.51.100.19"},
+					}},
+					AllocatedIPAddresses: &types.AllocatedIPAddresses{IPAddress: []string{
+						"198.51.100.11", "198.51.100.11", "198.51.100.200", "203.0.113.5",
+					}},
+				},
+				{
+					IPRanges: types.IPRanges{IPRange: []types.IPRange{
+						{StartAddress: "203.0.113.1", EndAddress: "203.0.113.10"},
+					}},
+					AllocatedIPAddresses: &types.AllocatedIPAddresses{IPAddress: []string{"198.51.100.12"}},
+					SubAllocations: &types.SubAllocations{SubAllocation: []types.SubAllocation{{
+						EdgeGateway: types.EdgeGateway{Name: "gateway"},
+						IPRanges: types.IPRanges{IPRange: []types.IPRange{
+							{StartAddress: "203.0.113.1", EndAddress: "203.0.113.5"},
+						}},
+					}}},
+				},
+			}
+
+			usage, err := n.IPUsage()
+			Convey("Only distinct allocations inside the static ranges and outside sub-allocations should be used", func() {
+				So(err, ShouldBeNil)
+				So(usage.Total, ShouldEqual, 20)
+				So(usage.Allocated, ShouldHaveLength, 5)
+				So(usage.SubAllocated["gateway"], ShouldHaveLength, 1)
+				So(usage.Free, ShouldEqual, 13)
+			})
+		})
+
+		Convey("When finding an external network that does not exist", func() {
+			_, err := FindExternalNetwork(c, "missing")
+			So(IsNotFound(err), ShouldBeTrue)
+		})
+
+		Convey("When creating an external network", func() {
+			n := &ExternalNetwork{
+				Name: "uplink-2",
+				VimPortGroupRef: &types.VimObjectRef{
+					VimServerRef:  types.Reference{Href: "https://vcloud.example.com/api/admin/extension/vimServer/1"},
+					MoRef:         "dvportgroup-43",
+					VimObjectType: "DV_PORTGROUP",
+				},
+			}
+			n.Configuration.IPScopes.IPScope = []types.IPScope{{
+				Gateway: "198.51.100.1",
+				Netmask: "255.255.255.0",
+				IPRanges: types.IPRanges{IPRange: []types.IPRange{
+					{StartAddress: "198.51.100.10", EndAddress: "198.51.100.99"},
+				}},
+			}}
+
+			created, err := CreateExternalNetwork(c, n)
+			Convey("The network should round trip", func() {
+				So(err, ShouldBeNil)
+				So(created.Name, ShouldEqual, "uplink-2")
+				So(created.Configuration.FenceMode, ShouldEqual, FenceModeIsolated)
+				So(created.VimPortGroupRef.MoRef, ShouldEqual, "dvportgroup-43")
+				So(created.Configuration.IPScopes.IPScope, ShouldHaveLength, 1)
+			})
+
+			Convey("A network without a port group should be rejected", func() {
+				n.VimPortGroupRef = nil
+				_, err := CreateExternalNetwork(c, n)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
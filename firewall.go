@@ -0,0 +1,143 @@
+package vcloud
+
+import (
+	"fmt"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// Firewall policies
+const (
+	FirewallPolicyAllow = "allow"
+	FirewallPolicyDrop  = "drop"
+)
+
+// FirewallRules ...
+func (g *EdgeGateway) FirewallRules() []t.FirewallRule {
+	fw := g.services().FirewallService
+	if fw == nil {
+		return nil
+	}
+	return fw.FirewallRules
+}
+
+// AddFirewallRule appends a rule to the end of the gateway's rule set.
+func (g *EdgeGateway) AddFirewallRule(rule t.FirewallRule) (*Task, error) {
+	err := validateFirewallRule(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(firewallService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		fw := services.FirewallService
+		fw.FirewallRules = append(fw.FirewallRules, rule)
+		return nil
+	})
+}
+
+// RemoveFirewallRule ...
+func (g *EdgeGateway) RemoveFirewallRule(id string) (*Task, error) {
+	return g.updateService(firewallService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		fw := services.FirewallService
+		i := findFirewallRule(fw.FirewallRules, id)
+		if i < 0 {
+			return fmt.Errorf("could not find firewall rule %s on edge gateway %s", id, g.Name)
+		}
+		fw.FirewallRules = append(fw.FirewallRules[:i], fw.FirewallRules[i+1:]...)
+		return nil
+	})
+}
+
+// MoveFirewallRule moves a rule to the given zero based position in the
+// rule set. Rules are evaluated in order.
+func (g *EdgeGateway) MoveFirewallRule(id string, position int) (*Task, error) {
+	return g.updateService(firewallService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		fw := services.FirewallService
+		i := findFirewallRule(fw.FirewallRules, id)
+		if i < 0 {
+			return fmt.Errorf("could not find firewall rule %s on edge gateway %s", id, g.Name)
+		}
+
+		if position < 0 || position >= len(fw.FirewallRules) {
+			return fmt.Errorf("firewall rule position %d is out of range", position)
+		}
+
+		rule := fw.FirewallRules[i]
+		rules := append(fw.FirewallRules[:i], fw.FirewallRules[i+1:]...)
+		rules = append(rules[:position], append([]t.FirewallRule{rule}, rules[position:]...)...)
+		fw.FirewallRules = rules
+
+		return nil
+	})
+}
+
+// SetFirewallRules replaces the gateway's rule set.
+func (g *EdgeGateway) SetFirewallRules(rules []t.FirewallRule) (*Task, error) {
+	for i := range rules {
+		err := validateFirewallRule(&rules[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return g.updateService(firewallService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		fw := services.FirewallService
+		fw.FirewallRules = append([]t.FirewallRule(nil), rules...)
+		return nil
+	})
+}
+
+// SetFirewallDefaultAction sets the policy applied to traffic that matches
+// no rule, and whether it is logged.
+func (g *EdgeGateway) SetFirewallDefaultAction(action string, logging bool) (*Task, error) {
+	if action != FirewallPolicyAllow && action != FirewallPolicyDrop {
+		return nil, fmt.Errorf("invalid firewall default action %s", action)
+	}
+
+	return g.updateService(firewallService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		fw := services.FirewallService
+		fw.DefaultAction = action
+		fw.LogDefaultAction = logging
+		return nil
+	})
+}
+
+// SetFirewallEnabled ...
+func (g *EdgeGateway) SetFirewallEnabled(enabled bool) (*Task, error) {
+	return g.updateService(firewallService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		fw := services.FirewallService
+		fw.IsEnabled = enabled
+		return nil
+	})
+}
+
+// firewallService selects the firewall service for updateService.
+func firewallService(dst, src *t.EdgeGatewayServiceConfiguration) {
+	fw := t.FirewallService{IsEnabled: true, DefaultAction: FirewallPolicyDrop}
+	if current := src.FirewallService; current != nil {
+		fw = *current
+		fw.FirewallRules = append([]t.FirewallRule(nil), current.FirewallRules...)
+	}
+	dst.FirewallService = &fw
+}
+
+func findFirewallRule(rules []t.FirewallRule, id string) int {
+	for i, rule := range rules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateFirewallRule(rule *t.FirewallRule) error {
+	if rule.Policy != FirewallPolicyAllow && rule.Policy != FirewallPolicyDrop {
+		return fmt.Errorf("invalid firewall rule policy %s", rule.Policy)
+	}
+
+	if rule.Protocols == nil {
+		return fmt.Errorf("firewall rule %s must specify its protocols", rule.Description)
+	}
+
+	return nil
+}
@@ -1,8 +1,14 @@
 package vcloud
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"strings"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
 )
 
 // ParseResponse ...
@@ -11,3 +17,93 @@ func ParseResponse(resp *http.Response) (*[]byte, error) {
 	data, err := ioutil.ReadAll(resp.Body)
 	return &data, err
 }
+
+func findActionLink(links []t.Link, action string) string {
+	for _, link := range links {
+		if strings.HasSuffix(link.Href, "/"+action) {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func findLinkByRel(links []t.Link, rel string) string {
+	for _, link := range links {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func parseIP(address string) (net.IP, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address %s", address)
+	}
+	return ip, nil
+}
+
+// parseIPRange parses a start and end address, checking that the start
+// does not come after the end.
+func parseIPRange(start, end string) (net.IP, net.IP, error) {
+	s, err := parseIP(start)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, err := parseIP(end)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if compareIPs(s, e) > 0 {
+		return nil, nil, fmt.Errorf("ip range start %s is after its end %s", start, end)
+	}
+
+	return s, e, nil
+}
+
+// parseSubnet builds the subnet described by a gateway and dotted netmask.
+func parseSubnet(gateway, netmask string) (*net.IPNet, error) {
+	gw, err := parseIP(gateway)
+	if err != nil {
+		return nil, err
+	}
+
+	mask, err := parseIP(netmask)
+	if err != nil || mask.To4() == nil {
+		return nil, fmt.Errorf("invalid netmask %s", netmask)
+	}
+
+	m := net.IPMask(mask.To4())
+	if ones, bits := m.Size(); ones == 0 && bits == 0 {
+		return nil, fmt.Errorf("invalid netmask %s", netmask)
+	}
+
+	return &net.IPNet{IP: gw.To4().Mask(m), Mask: m}, nil
+}
+
+func compareIPs(a, b net.IP) int {
+	return bytes.Compare(a.To16(), b.To16())
+}
+
+func ipRangesOverlap(aStart, aEnd, bStart, bEnd net.IP) bool {
+	return compareIPs(aStart, bEnd) <= 0 && compareIPs(bStart, aEnd) <= 0
+}
+
+func ipInRange(ip, start, end net.IP) bool {
+	return compareIPs(ip, start) >= 0 && compareIPs(ip, end) <= 0
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
@@ -0,0 +1,271 @@
+package vcloud
+
+import (
+	"fmt"
+	"net"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// subnetPrefixLengthVersion is the api version that introduced ip scope
+// subnet prefix lengths.
+const subnetPrefixLengthVersion = "31.0"
+
+// IPScopes ...
+func (n *Network) IPScopes() []t.IPScope {
+	return n.Configuration.IPScopes.IPScope
+}
+
+// GetIPScope returns the ip scope with the given gateway address.
+func (n *Network) GetIPScope(gateway string) (*t.IPScope, error) {
+	i := n.findIPScope(gateway)
+	if i < 0 {
+		return nil, fmt.Errorf("network %s has no ip scope with gateway %s", n.Name, gateway)
+	}
+	return &n.Configuration.IPScopes.IPScope[i], nil
+}
+
+// AddIPScope adds an ip scope. Scopes are identified by their gateway and
+// must use either a netmask or, for ipv6, a subnet prefix length.
+func (n *Network) AddIPScope(scope t.IPScope) error {
+	if n.findIPScope(scope.Gateway) >= 0 {
+		return fmt.Errorf("network %s already has an ip scope with gateway %s", n.Name, scope.Gateway)
+	}
+
+	scopes := append(append([]t.IPScope(nil), n.IPScopes()...), scope)
+
+	err := validateIPScopes(scopes)
+	if err != nil {
+		return err
+	}
+
+	n.Configuration.IPScopes.IPScope = scopes
+
+	return nil
+}
+
+// UpdateIPScope replaces the ip scope with the given gateway address.
+func (n *Network) UpdateIPScope(gateway string, scope t.IPScope) error {
+	i := n.findIPScope(gateway)
+	if i < 0 {
+		return fmt.Errorf("network %s has no ip scope with gateway %s", n.Name, gateway)
+	}
+
+	scopes := append([]t.IPScope(nil), n.IPScopes()...)
+	scopes[i] = scope
+
+	err := validateIPScopes(scopes)
+	if err != nil {
+		return err
+	}
+
+	n.Configuration.IPScopes.IPScope = scopes
+
+	return nil
+}
+
+// RemoveIPScope ...
+func (n *Network) RemoveIPScope(gateway string) error {
+	i := n.findIPScope(gateway)
+	if i < 0 {
+		return fmt.Errorf("network %s has no ip scope with gateway %s", n.Name, gateway)
+	}
+
+	scopes := n.Configuration.IPScopes.IPScope
+	n.Configuration.IPScopes.IPScope = append(scopes[:i], scopes[i+1:]...)
+
+	return nil
+}
+
+// IPRanges returns the static ip ranges of the ip scope with the given
+// gateway address.
+func (n *Network) IPRanges(gateway string) ([]t.IPRange, error) {
+	scope, err := n.GetIPScope(gateway)
+	if err != nil {
+		return nil, err
+	}
+	return scope.IPRanges.IPRange, nil
+}
+
+// AddIPRange adds a static ip range to the ip scope with the given gateway
+// address.
+func (n *Network) AddIPRange(gateway, start, end string) error {
+	return n.updateIPRanges(gateway, func(ranges []t.IPRange) ([]t.IPRange, error) {
+		return append(ranges, t.IPRange{StartAddress: start, EndAddress: end}), nil
+	})
+}
+
+// UpdateIPRange replaces the static ip range starting at oldStart.
+func (n *Network) UpdateIPRange(gateway, oldStart, start, end string) error {
+	return n.updateIPRanges(gateway, func(ranges []t.IPRange) ([]t.IPRange, error) {
+		i := findIPRange(ranges, oldStart)
+		if i < 0 {
+			return nil, fmt.Errorf("network %s has no ip range starting at %s", n.Name, oldStart)
+		}
+		ranges[i].StartAddress = start
+		ranges[i].EndAddress = end
+		return ranges, nil
+	})
+}
+
+// RemoveIPRange removes the static ip range starting at start.
+func (n *Network) RemoveIPRange(gateway, start string) error {
+	return n.updateIPRanges(gateway, func(ranges []t.IPRange) ([]t.IPRange, error) {
+		i := findIPRange(ranges, start)
+		if i < 0 {
+			return nil, fmt.Errorf("network %s has no ip range starting at %s", n.Name, start)
+		}
+		return append(ranges[:i], ranges[i+1:]...), nil
+	})
+}
+
+// ValidateIPScopes checks that every ip range is inside its scope's subnet,
+// excludes the gateway and does not overlap any other range, and that no two
+// scopes overlap.
+func (n *Network) ValidateIPScopes() error {
+	err := validateIPScopes(n.IPScopes())
+	if err != nil {
+		return fmt.Errorf("network %s: %s", n.Name, err)
+	}
+	return nil
+}
+
+// checkIPScopesVersion checks that the negotiated api version supports the
+// network's ip scopes.
+func (n *Network) checkIPScopesVersion(c *Connector) error {
+	for _, scope := range n.IPScopes() {
+		if scope.SubnetPrefixLength > 0 && !c.SupportsVersion(subnetPrefixLengthVersion) {
+			return fmt.Errorf("network %s: ip scope %s subnet prefix length requires api version %s, but %s was negotiated", n.Name, scope.Gateway, subnetPrefixLengthVersion, c.APIVersion())
+		}
+	}
+	return nil
+}
+
+// updateIPRanges applies fn to a copy of a scope's ranges, only keeping the
+// result if the scope is still valid.
+func (n *Network) updateIPRanges(gateway string, fn func(ranges []t.IPRange) ([]t.IPRange, error)) error {
+	i := n.findIPScope(gateway)
+	if i < 0 {
+		return fmt.Errorf("network %s has no ip scope with gateway %s", n.Name, gateway)
+	}
+
+	scopes := append([]t.IPScope(nil), n.IPScopes()...)
+
+	ranges, err := fn(append([]t.IPRange(nil), scopes[i].IPRanges.IPRange...))
+	if err != nil {
+		return err
+	}
+	scopes[i].IPRanges.IPRange = ranges
+
+	err = validateIPScopes(scopes)
+	if err != nil {
+		return err
+	}
+
+	n.Configuration.IPScopes.IPScope = scopes
+
+	return nil
+}
+
+func (n *Network) findIPScope(gateway string) int {
+	for i, scope := range n.IPScopes() {
+		if scope.Gateway == gateway {
+			return i
+		}
+	}
+	return -1
+}
+
+func findIPRange(ranges []t.IPRange, start string) int {
+	for i, r := range ranges {
+		if r.StartAddress == start {
+			return i
+		}
+	}
+	return -1
+}
+
+// scopeSubnet returns the subnet of an ip scope from either its netmask or
+// its subnet prefix length.
+func scopeSubnet(scope *t.IPScope) (*net.IPNet, error) {
+	if scope.Netmask != "" {
+		return parseSubnet(scope.Gateway, scope.Netmask)
+	}
+
+	gw, err := parseIP(scope.Gateway)
+	if err != nil {
+		return nil, err
+	}
+
+	bits := net.IPv6len * 8
+	if gw.To4() != nil {
+		gw = gw.To4()
+		bits = net.IPv4len * 8
+	}
+
+	if scope.SubnetPrefixLength < 1 || scope.SubnetPrefixLength > bits {
+		return nil, fmt.Errorf("ip scope %s must have a netmask or a valid subnet prefix length", scope.Gateway)
+	}
+
+	mask := net.CIDRMask(scope.SubnetPrefixLength, bits)
+
+	return &net.IPNet{IP: gw.Mask(mask), Mask: mask}, nil
+}
+
+func validateIPScopes(scopes []t.IPScope) error {
+	subnets := make([]*net.IPNet, len(scopes))
+
+	for i := range scopes {
+		subnet, err := validateIPScope(&scopes[i])
+		if err != nil {
+			return err
+		}
+
+		for j := 0; j < i; j++ {
+			if subnets[j].Contains(subnet.IP) || subnet.Contains(subnets[j].IP) {
+				return fmt.Errorf("ip scope %s overlaps ip scope %s", scopes[i].Gateway, scopes[j].Gateway)
+			}
+		}
+
+		subnets[i] = subnet
+	}
+
+	return nil
+}
+
+func validateIPScope(scope *t.IPScope) (*net.IPNet, error) {
+	subnet, err := scopeSubnet(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	gw, _ := parseIP(scope.Gateway)
+	ranges := scope.IPRanges.IPRange
+	starts := make([]net.IP, len(ranges))
+	ends := make([]net.IP, len(ranges))
+
+	for i, r := range ranges {
+		start, end, err := parseIPRange(r.StartAddress, r.EndAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		if !subnet.Contains(start) || !subnet.Contains(end) {
+			return nil, fmt.Errorf("ip range %s-%s is outside of subnet %s", r.StartAddress, r.EndAddress, subnet)
+		}
+
+		if ipInRange(gw, start, end) {
+			return nil, fmt.Errorf("ip range %s-%s includes the gateway address %s", r.StartAddress, r.EndAddress, scope.Gateway)
+		}
+
+		for j := 0; j < i; j++ {
+			if ipRangesOverlap(start, end, starts[j], ends[j]) {
+				return nil, fmt.Errorf("ip range %s-%s overlaps ip range %s-%s", r.StartAddress, r.EndAddress, ranges[j].StartAddress, ranges[j].EndAddress)
+			}
+		}
+
+		starts[i], ends[i] = start, end
+	}
+
+	return subnet, nil
+}
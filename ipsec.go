@@ -0,0 +1,256 @@
+package vcloud
+
+import (
+	"fmt"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// IPsec VPN encryption protocols
+const (
+	IpsecEncryptionAES       = "AES"
+	IpsecEncryptionAES256    = "AES256"
+	IpsecEncryptionTripleDES = "TRIPLEDES"
+)
+
+const defaultIpsecMtu = 1500
+
+// IpsecVpnTunnels ...
+func (g *EdgeGateway) IpsecVpnTunnels() []t.GatewayIpsecVpnTunnel {
+	vpn := g.services().GatewayIpsecVpnService
+	if vpn == nil {
+		return nil
+	}
+	return vpn.Tunnels
+}
+
+// IpsecVpnEndpoints ...
+func (g *EdgeGateway) IpsecVpnEndpoints() []t.GatewayIpsecVpnEndpoint {
+	vpn := g.services().GatewayIpsecVpnService
+	if vpn == nil {
+		return nil
+	}
+	return vpn.Endpoints
+}
+
+// GetIpsecVpnTunnel ...
+func (g *EdgeGateway) GetIpsecVpnTunnel(name string) (*t.GatewayIpsecVpnTunnel, error) {
+	tunnels := g.IpsecVpnTunnels()
+	i := findIpsecVpnTunnel(tunnels, name)
+	if i < 0 {
+		return nil, fmt.Errorf("could not find ipsec vpn tunnel %s on edge gateway %s", name, g.Name)
+	}
+	return &tunnels[i], nil
+}
+
+// SetIpsecVpnEndpoint terminates tunnels on publicIP on the named network's
+// interface, replacing any existing endpoint on that network.
+func (g *EdgeGateway) SetIpsecVpnEndpoint(network, publicIP string) (*Task, error) {
+	iface, err := g.gatewayInterface(network)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = parseIP(publicIP)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := t.GatewayIpsecVpnEndpoint{
+		Network: t.Reference{
+			Href: iface.Network.Href,
+			Type: iface.Network.Type,
+			Name: iface.Network.Name,
+		},
+		PublicIP: publicIP,
+	}
+
+	return g.updateService(ipsecVpnService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		vpn := services.GatewayIpsecVpnService
+		for i, e := range vpn.Endpoints {
+			if e.Network.Href == endpoint.Network.Href {
+				vpn.Endpoints[i] = endpoint
+				return nil
+			}
+		}
+		vpn.Endpoints = append(vpn.Endpoints, endpoint)
+		return nil
+	})
+}
+
+// AddIpsecVpnTunnel adds a tunnel. Its local ip must belong to one of the
+// gateway's vpn endpoints.
+func (g *EdgeGateway) AddIpsecVpnTunnel(tunnel t.GatewayIpsecVpnTunnel) (*Task, error) {
+	err := validateIpsecVpnTunnel(&tunnel)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(ipsecVpnService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		vpn := services.GatewayIpsecVpnService
+		if findIpsecVpnTunnel(vpn.Tunnels, tunnel.Name) >= 0 {
+			return fmt.Errorf("ipsec vpn tunnel %s already exists on edge gateway %s", tunnel.Name, g.Name)
+		}
+
+		err := checkIpsecVpnEndpoint(vpn, &tunnel)
+		if err != nil {
+			return err
+		}
+
+		vpn.Tunnels = append(vpn.Tunnels, tunnel)
+		return nil
+	})
+}
+
+// UpdateIpsecVpnTunnel replaces the tunnel with the given name.
+func (g *EdgeGateway) UpdateIpsecVpnTunnel(name string, tunnel t.GatewayIpsecVpnTunnel) (*Task, error) {
+	err := validateIpsecVpnTunnel(&tunnel)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(ipsecVpnService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		vpn := services.GatewayIpsecVpnService
+		i := findIpsecVpnTunnel(vpn.Tunnels, name)
+		if i < 0 {
+			return fmt.Errorf("could not find ipsec vpn tunnel %s on edge gateway %s", name, g.Name)
+		}
+
+		if tunnel.Name != name && findIpsecVpnTunnel(vpn.Tunnels, tunnel.Name) >= 0 {
+			return fmt.Errorf("ipsec vpn tunnel %s already exists on edge gateway %s", tunnel.Name, g.Name)
+		}
+
+		err := checkIpsecVpnEndpoint(vpn, &tunnel)
+		if err != nil {
+			return err
+		}
+
+		vpn.Tunnels[i] = tunnel
+		return nil
+	})
+}
+
+// RemoveIpsecVpnTunnel ...
+func (g *EdgeGateway) RemoveIpsecVpnTunnel(name string) (*Task, error) {
+	return g.updateService(ipsecVpnService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		vpn := services.GatewayIpsecVpnService
+		i := findIpsecVpnTunnel(vpn.Tunnels, name)
+		if i < 0 {
+			return fmt.Errorf("could not find ipsec vpn tunnel %s on edge gateway %s", name, g.Name)
+		}
+		vpn.Tunnels = append(vpn.Tunnels[:i], vpn.Tunnels[i+1:]...)
+		return nil
+	})
+}
+
+// SetIpsecVpnEnabled ...
+func (g *EdgeGateway) SetIpsecVpnEnabled(enabled bool) (*Task, error) {
+	return g.updateService(ipsecVpnService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		vpn := services.GatewayIpsecVpnService
+		vpn.IsEnabled = enabled
+		return nil
+	})
+}
+
+// IpsecVpnTunnelStatus fetches the current state of the named tunnel from the
+// gateway, reporting whether it is operational and any error details.
+func (g *EdgeGateway) IpsecVpnTunnelStatus(name string) (bool, string, error) {
+	current, err := NewEdgeGateway(g.Connector, g.Href)
+	if err != nil {
+		return false, "", err
+	}
+
+	tunnel, err := current.GetIpsecVpnTunnel(name)
+	if err != nil {
+		return false, "", err
+	}
+
+	return tunnel.IsOperational, tunnel.ErrorDetails, nil
+}
+
+// ipsecVpnService selects the ipsec vpn service for updateService.
+func ipsecVpnService(dst, src *t.EdgeGatewayServiceConfiguration) {
+	vpn := t.GatewayIpsecVpnService{IsEnabled: true}
+	if current := src.GatewayIpsecVpnService; current != nil {
+		vpn = *current
+		vpn.Endpoints = append([]t.GatewayIpsecVpnEndpoint(nil), current.Endpoints...)
+		vpn.Tunnels = append([]t.GatewayIpsecVpnTunnel(nil), current.Tunnels...)
+	}
+	dst.GatewayIpsecVpnService = &vpn
+}
+
+func findIpsecVpnTunnel(tunnels []t.GatewayIpsecVpnTunnel, name string) int {
+	for i, tunnel := range tunnels {
+		if tunnel.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func checkIpsecVpnEndpoint(vpn *t.GatewayIpsecVpnService, tunnel *t.GatewayIpsecVpnTunnel) error {
+	for _, e := range vpn.Endpoints {
+		if e.PublicIP == tunnel.LocalIPAddress {
+			return nil
+		}
+	}
+	return fmt.Errorf("ipsec vpn tunnel %s local ip %s is not a vpn endpoint", tunnel.Name, tunnel.LocalIPAddress)
+}
+
+func validateIpsecVpnTunnel(tunnel *t.GatewayIpsecVpnTunnel) error {
+	if tunnel.Name == "" {
+		return fmt.Errorf("ipsec vpn tunnel must have a name")
+	}
+
+	for _, ip := range []string{tunnel.PeerIPAddress, tunnel.LocalIPAddress} {
+		_, err := parseIP(ip)
+		if err != nil {
+			return fmt.Errorf("ipsec vpn tunnel %s: %s", tunnel.Name, err)
+		}
+	}
+
+	if tunnel.PeerID == "" {
+		tunnel.PeerID = tunnel.PeerIPAddress
+	}
+
+	if tunnel.LocalID == "" {
+		tunnel.LocalID = tunnel.LocalIPAddress
+	}
+
+	if len(tunnel.LocalSubnets) < 1 || len(tunnel.PeerSubnets) < 1 {
+		return fmt.Errorf("ipsec vpn tunnel %s must specify local and peer subnets", tunnel.Name)
+	}
+
+	for _, subnets := range [][]t.IpsecVpnSubnet{tunnel.LocalSubnets, tunnel.PeerSubnets} {
+		for _, subnet := range subnets {
+			_, err := parseSubnet(subnet.Gateway, subnet.Netmask)
+			if err != nil {
+				return fmt.Errorf("ipsec vpn tunnel %s subnet %s: %s", tunnel.Name, subnet.Name, err)
+			}
+		}
+	}
+
+	if tunnel.SharedSecret == "" && !tunnel.SharedSecretEncrypted {
+		return fmt.Errorf("ipsec vpn tunnel %s must specify a shared secret", tunnel.Name)
+	}
+
+	switch tunnel.EncryptionProtocol {
+	case IpsecEncryptionAES, IpsecEncryptionAES256, IpsecEncryptionTripleDES:
+	default:
+		return fmt.Errorf("invalid ipsec vpn encryption protocol %s", tunnel.EncryptionProtocol)
+	}
+
+	if tunnel.Mtu == 0 {
+		tunnel.Mtu = defaultIpsecMtu
+	}
+
+	if tunnel.Mtu < 64 || tunnel.Mtu > defaultIpsecMtu {
+		return fmt.Errorf("ipsec vpn tunnel %s mtu %d is out of range", tunnel.Name, tunnel.Mtu)
+	}
+
+	if tunnel.IpsecVpnThirdPartyPeer == nil {
+		tunnel.IpsecVpnThirdPartyPeer = &t.IpsecVpnThirdPartyPeer{}
+	}
+
+	return nil
+}
@@ -0,0 +1,362 @@
+package vcloud
+
+import (
+	"fmt"
+	"strconv"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// Load balancer protocols
+const (
+	LoadBalancerProtocolHTTP  = "HTTP"
+	LoadBalancerProtocolHTTPS = "HTTPS"
+	LoadBalancerProtocolTCP   = "TCP"
+)
+
+// Load balancer pool algorithms
+const (
+	LoadBalancerAlgorithmRoundRobin     = "ROUND_ROBIN"
+	LoadBalancerAlgorithmIPHash         = "IP_HASH"
+	LoadBalancerAlgorithmURI            = "URI"
+	LoadBalancerAlgorithmLeastConnected = "LEAST_CONNECTED"
+)
+
+// Load balancer health check modes
+const (
+	LoadBalancerHealthCheckTCP  = "TCP"
+	LoadBalancerHealthCheckHTTP = "HTTP"
+	LoadBalancerHealthCheckSSL  = "SSL"
+)
+
+// Load balancer persistence methods
+const (
+	LoadBalancerPersistenceCookie       = "COOKIE"
+	LoadBalancerPersistenceSSLSessionID = "SSL_SESSION_ID"
+)
+
+// LoadBalancerPools ...
+func (g *EdgeGateway) LoadBalancerPools() []t.LoadBalancerPool {
+	lb := g.services().LoadBalancerService
+	if lb == nil {
+		return nil
+	}
+	return lb.Pools
+}
+
+// LoadBalancerVirtualServers ...
+func (g *EdgeGateway) LoadBalancerVirtualServers() []t.LoadBalancerVirtualServer {
+	lb := g.services().LoadBalancerService
+	if lb == nil {
+		return nil
+	}
+	return lb.VirtualServers
+}
+
+// AddLoadBalancerPool ...
+func (g *EdgeGateway) AddLoadBalancerPool(pool t.LoadBalancerPool) (*Task, error) {
+	err := validateLoadBalancerPool(&pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(loadBalancerService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		lb := services.LoadBalancerService
+		if findLoadBalancerPool(lb.Pools, pool.Name) >= 0 {
+			return fmt.Errorf("load balancer pool %s already exists on edge gateway %s", pool.Name, g.Name)
+		}
+		lb.Pools = append(lb.Pools, pool)
+		return nil
+	})
+}
+
+// UpdateLoadBalancerPool replaces the pool with the given name. If the pool
+// is renamed, any virtual servers using it are updated to the new name.
+func (g *EdgeGateway) UpdateLoadBalancerPool(name string, pool t.LoadBalancerPool) (*Task, error) {
+	err := validateLoadBalancerPool(&pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(loadBalancerService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		lb := services.LoadBalancerService
+		i := findLoadBalancerPool(lb.Pools, name)
+		if i < 0 {
+			return fmt.Errorf("could not find load balancer pool %s on edge gateway %s", name, g.Name)
+		}
+
+		if pool.Name != name {
+			if findLoadBalancerPool(lb.Pools, pool.Name) >= 0 {
+				return fmt.Errorf("load balancer pool %s already exists on edge gateway %s", pool.Name, g.Name)
+			}
+
+			for j := range lb.VirtualServers {
+				if lb.VirtualServers[j].Pool == name {
+					lb.VirtualServers[j].Pool = pool.Name
+				}
+			}
+		}
+
+		pool.ID = lb.Pools[i].ID
+		lb.Pools[i] = pool
+		return nil
+	})
+}
+
+// RemoveLoadBalancerPool removes a pool. Pools still used by a virtual
+// server cannot be removed.
+func (g *EdgeGateway) RemoveLoadBalancerPool(name string) (*Task, error) {
+	return g.updateService(loadBalancerService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		lb := services.LoadBalancerService
+		i := findLoadBalancerPool(lb.Pools, name)
+		if i < 0 {
+			return fmt.Errorf("could not find load balancer pool %s on edge gateway %s", name, g.Name)
+		}
+
+		for _, vs := range lb.VirtualServers {
+			if vs.Pool == name {
+				return fmt.Errorf("load balancer pool %s is in use by virtual server %s", name, vs.Name)
+			}
+		}
+
+		lb.Pools = append(lb.Pools[:i], lb.Pools[i+1:]...)
+		return nil
+	})
+}
+
+// AddLoadBalancerVirtualServer adds a virtual server on the named uplink
+// network's interface.
+func (g *EdgeGateway) AddLoadBalancerVirtualServer(network string, vs t.LoadBalancerVirtualServer) (*Task, error) {
+	iface, err := g.gatewayInterface(network)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface.InterfaceType != "uplink" {
+		return nil, fmt.Errorf("load balancer virtual servers must be on an uplink interface, %s is %s", network, iface.InterfaceType)
+	}
+
+	vs.Interface = &t.Reference{
+		Href: iface.Network.Href,
+		Type: iface.Network.Type,
+		Name: iface.Network.Name,
+	}
+
+	err = validateLoadBalancerVirtualServer(&vs)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(loadBalancerService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		lb := services.LoadBalancerService
+		if findLoadBalancerVirtualServer(lb.VirtualServers, vs.Name) >= 0 {
+			return fmt.Errorf("load balancer virtual server %s already exists on edge gateway %s", vs.Name, g.Name)
+		}
+
+		if findLoadBalancerPool(lb.Pools, vs.Pool) < 0 {
+			return fmt.Errorf("could not find load balancer pool %s on edge gateway %s", vs.Pool, g.Name)
+		}
+
+		lb.VirtualServers = append(lb.VirtualServers, vs)
+		return nil
+	})
+}
+
+// UpdateLoadBalancerVirtualServer replaces the virtual server with the given
+// name. The existing interface is kept if none is specified.
+func (g *EdgeGateway) UpdateLoadBalancerVirtualServer(name string, vs t.LoadBalancerVirtualServer) (*Task, error) {
+	return g.updateService(loadBalancerService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		lb := services.LoadBalancerService
+		i := findLoadBalancerVirtualServer(lb.VirtualServers, name)
+		if i < 0 {
+			return fmt.Errorf("could not find load balancer virtual server %s on edge gateway %s", name, g.Name)
+		}
+
+		if vs.Name != name && findLoadBalancerVirtualServer(lb.VirtualServers, vs.Name) >= 0 {
+			return fmt.Errorf("load balancer virtual server %s already exists on edge gateway %s", vs.Name, g.Name)
+		}
+
+		if vs.Interface == nil {
+			vs.Interface = lb.VirtualServers[i].Interface
+		}
+
+		err := validateLoadBalancerVirtualServer(&vs)
+		if err != nil {
+			return err
+		}
+
+		if findLoadBalancerPool(lb.Pools, vs.Pool) < 0 {
+			return fmt.Errorf("could not find load balancer pool %s on edge gateway %s", vs.Pool, g.Name)
+		}
+
+		lb.VirtualServers[i] = vs
+		return nil
+	})
+}
+
+// RemoveLoadBalancerVirtualServer ...
+func (g *EdgeGateway) RemoveLoadBalancerVirtualServer(name string) (*Task, error) {
+	return g.updateService(loadBalancerService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		lb := services.LoadBalancerService
+		i := findLoadBalancerVirtualServer(lb.VirtualServers, name)
+		if i < 0 {
+			return fmt.Errorf("could not find load balancer virtual server %s on edge gateway %s", name, g.Name)
+		}
+		lb.VirtualServers = append(lb.VirtualServers[:i], lb.VirtualServers[i+1:]...)
+		return nil
+	})
+}
+
+// SetLoadBalancerEnabled ...
+func (g *EdgeGateway) SetLoadBalancerEnabled(enabled bool) (*Task, error) {
+	return g.updateService(loadBalancerService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		lb := services.LoadBalancerService
+		lb.IsEnabled = enabled
+		return nil
+	})
+}
+
+// loadBalancerService selects the load balancer service for updateService.
+func loadBalancerService(dst, src *t.EdgeGatewayServiceConfiguration) {
+	lb := t.LoadBalancerService{IsEnabled: true}
+	if current := src.LoadBalancerService; current != nil {
+		lb = *current
+		lb.Pools = append([]t.LoadBalancerPool(nil), current.Pools...)
+		lb.VirtualServers = append([]t.LoadBalancerVirtualServer(nil), current.VirtualServers...)
+	}
+	dst.LoadBalancerService = &lb
+}
+
+func findLoadBalancerPool(pools []t.LoadBalancerPool, name string) int {
+	for i, pool := range pools {
+		if pool.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func findLoadBalancerVirtualServer(servers []t.LoadBalancerVirtualServer, name string) int {
+	for i, vs := range servers {
+		if vs.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateLoadBalancerPool(pool *t.LoadBalancerPool) error {
+	if pool.Name == "" {
+		return fmt.Errorf("load balancer pool must have a name")
+	}
+
+	if len(pool.ServicePorts) < 1 {
+		return fmt.Errorf("load balancer pool %s must specify at least one service port", pool.Name)
+	}
+
+	for _, sp := range pool.ServicePorts {
+		err := validateLoadBalancerPoolPort(&sp)
+		if err != nil {
+			return fmt.Errorf("load balancer pool %s: %s", pool.Name, err)
+		}
+
+		if sp.Algorithm != LoadBalancerAlgorithmRoundRobin &&
+			sp.Algorithm != LoadBalancerAlgorithmIPHash &&
+			sp.Algorithm != LoadBalancerAlgorithmURI &&
+			sp.Algorithm != LoadBalancerAlgorithmLeastConnected {
+			return fmt.Errorf("load balancer pool %s: invalid algorithm %s", pool.Name, sp.Algorithm)
+		}
+
+		for _, hc := range sp.HealthChecks {
+			if hc.Mode != LoadBalancerHealthCheckTCP && hc.Mode != LoadBalancerHealthCheckHTTP && hc.Mode != LoadBalancerHealthCheckSSL {
+				return fmt.Errorf("load balancer pool %s: invalid health check mode %s", pool.Name, hc.Mode)
+			}
+		}
+	}
+
+	for _, m := range pool.Members {
+		_, err := parseIP(m.IPAddress)
+		if err != nil {
+			return fmt.Errorf("load balancer pool %s member: %s", pool.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateLoadBalancerPoolPort(sp *t.LoadBalancerPoolPort) error {
+	err := validateLoadBalancerProtocol(sp.Protocol)
+	if err != nil {
+		return err
+	}
+
+	if sp.Port != "" {
+		err = validateLoadBalancerPort(sp.Port)
+	}
+
+	return err
+}
+
+func validateLoadBalancerVirtualServer(vs *t.LoadBalancerVirtualServer) error {
+	if vs.Name == "" {
+		return fmt.Errorf("load balancer virtual server must have a name")
+	}
+
+	if vs.Interface == nil || vs.Interface.Href == "" {
+		return fmt.Errorf("load balancer virtual server %s must specify a gateway interface", vs.Name)
+	}
+
+	_, err := parseIP(vs.IPAddress)
+	if err != nil {
+		return fmt.Errorf("load balancer virtual server %s: %s", vs.Name, err)
+	}
+
+	if vs.Pool == "" {
+		return fmt.Errorf("load balancer virtual server %s must specify a pool", vs.Name)
+	}
+
+	for _, sp := range vs.ServiceProfiles {
+		err = validateLoadBalancerProtocol(sp.Protocol)
+		if err == nil {
+			err = validateLoadBalancerPort(sp.Port)
+		}
+		if err != nil {
+			return fmt.Errorf("load balancer virtual server %s: %s", vs.Name, err)
+		}
+
+		if sp.Persistence == nil || sp.Persistence.Method == "" {
+			continue
+		}
+
+		switch sp.Persistence.Method {
+		case LoadBalancerPersistenceCookie:
+			if sp.Persistence.CookieName == "" {
+				return fmt.Errorf("load balancer virtual server %s: cookie persistence requires a cookie name", vs.Name)
+			}
+		case LoadBalancerPersistenceSSLSessionID:
+			if sp.Protocol != LoadBalancerProtocolHTTPS {
+				return fmt.Errorf("load balancer virtual server %s: ssl session persistence requires https", vs.Name)
+			}
+		default:
+			return fmt.Errorf("load balancer virtual server %s: invalid persistence method %s", vs.Name, sp.Persistence.Method)
+		}
+	}
+
+	return nil
+}
+
+func validateLoadBalancerProtocol(protocol string) error {
+	switch protocol {
+	case LoadBalancerProtocolHTTP, LoadBalancerProtocolHTTPS, LoadBalancerProtocolTCP:
+		return nil
+	}
+	return fmt.Errorf("invalid load balancer protocol %s", protocol)
+}
+
+func validateLoadBalancerPort(port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid load balancer port %s", port)
+	}
+	return nil
+}
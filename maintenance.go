@@ -0,0 +1,86 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// Edge gateway form factors
+const (
+	GatewayTypeCompact = "compact"
+	GatewayTypeFull    = "full"
+	GatewayTypeFull4   = "full4"
+	GatewayTypeXLarge  = "x-large"
+)
+
+// Redeploy ...
+func (g *EdgeGateway) Redeploy() (*Task, error) {
+	return g.action("action/redeploy", nil, "")
+}
+
+// SyncSyslogServerSettings pushes the organization's syslog server settings
+// to the gateway.
+func (g *EdgeGateway) SyncSyslogServerSettings() (*Task, error) {
+	return g.action("action/syncSyslogServerSettings", nil, "")
+}
+
+// UpgradeConfig upgrades a compact gateway to the full configuration.
+func (g *EdgeGateway) UpgradeConfig() (*Task, error) {
+	return g.action("action/upgradeConfig", nil, "")
+}
+
+// ModifyFormFactor resizes the gateway to one of the GatewayType form
+// factors.
+func (g *EdgeGateway) ModifyFormFactor(gatewayType string) (*Task, error) {
+	switch gatewayType {
+	case GatewayTypeCompact, GatewayTypeFull, GatewayTypeFull4, GatewayTypeXLarge:
+	default:
+		return nil, fmt.Errorf("invalid edge gateway form factor %s", gatewayType)
+	}
+
+	data, err := xml.Marshal(t.EdgeGatewayFormFactor{GatewayType: gatewayType})
+	if err != nil {
+		return nil, err
+	}
+
+	return g.action("action/modifyFormFactor", data, edgeGatewayFormFactorType)
+}
+
+// SetHaEnabled enables or disables high availability, deploying a standby
+// appliance for the gateway.
+func (g *EdgeGateway) SetHaEnabled(enabled bool) (*Task, error) {
+	return g.update(func(config *t.GatewayConfiguration) error {
+		config.HaEnabled = enabled
+		return nil
+	})
+}
+
+// EnableDistributedRouting ...
+func (g *EdgeGateway) EnableDistributedRouting() (*Task, error) {
+	return g.action("action/enableDistributedRouting", nil, "")
+}
+
+// DisableDistributedRouting ...
+func (g *EdgeGateway) DisableDistributedRouting() (*Task, error) {
+	return g.action("action/disableDistributedRouting", nil, "")
+}
+
+// ConvertToAdvanced converts the gateway to advanced networking. This can
+// not be undone.
+func (g *EdgeGateway) ConvertToAdvanced() (*Task, error) {
+	return g.action("action/convertToAdvancedGateway", nil, "")
+}
+
+// AdvancedNetworkingEnabled ...
+func (g *EdgeGateway) AdvancedNetworkingEnabled() bool {
+	enabled := g.Configuration.AdvancedNetworkingEnabled
+	return enabled != nil && *enabled
+}
+
+// DistributedRoutingEnabled ...
+func (g *EdgeGateway) DistributedRoutingEnabled() bool {
+	enabled := g.Configuration.DistributedRoutingEnabled
+	return enabled != nil && *enabled
+}
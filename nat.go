@@ -0,0 +1,231 @@
+package vcloud
+
+import (
+	"fmt"
+	"strings"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// NAT rule types
+const (
+	NatRuleTypeSNAT = "SNAT"
+	NatRuleTypeDNAT = "DNAT"
+)
+
+// NatRules ...
+func (g *EdgeGateway) NatRules() []t.NatRule {
+	nat := g.services().NatService
+	if nat == nil {
+		return nil
+	}
+	return nat.NatRules
+}
+
+// SNATRules ...
+func (g *EdgeGateway) SNATRules() []t.NatRule {
+	return g.natRulesOfType(NatRuleTypeSNAT)
+}
+
+// DNATRules ...
+func (g *EdgeGateway) DNATRules() []t.NatRule {
+	return g.natRulesOfType(NatRuleTypeDNAT)
+}
+
+// AddNatRule ...
+func (g *EdgeGateway) AddNatRule(rule t.NatRule) (*Task, error) {
+	err := validateNatRule(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(natService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		nat := services.NatService
+		nat.NatRules = append(nat.NatRules, rule)
+		return nil
+	})
+}
+
+// UpdateNatRule replaces the rule with the given id.
+func (g *EdgeGateway) UpdateNatRule(id string, rule t.NatRule) (*Task, error) {
+	err := validateNatRule(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.updateService(natService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		nat := services.NatService
+		i := findNatRule(nat.NatRules, id)
+		if i < 0 {
+			return fmt.Errorf("could not find nat rule %s on edge gateway %s", id, g.Name)
+		}
+		rule.ID = id
+		nat.NatRules[i] = rule
+		return nil
+	})
+}
+
+// RemoveNatRule ...
+func (g *EdgeGateway) RemoveNatRule(id string) (*Task, error) {
+	return g.updateService(natService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		nat := services.NatService
+		i := findNatRule(nat.NatRules, id)
+		if i < 0 {
+			return fmt.Errorf("could not find nat rule %s on edge gateway %s", id, g.Name)
+		}
+		nat.NatRules = append(nat.NatRules[:i], nat.NatRules[i+1:]...)
+		return nil
+	})
+}
+
+// AddDNAT translates traffic arriving at externalIP and externalPort on the
+// named network's interface to internalIP and internalPort.
+func (g *EdgeGateway) AddDNAT(network, externalIP, externalPort, internalIP, internalPort, protocol string) (*Task, error) {
+	rule, err := g.newNatRule(NatRuleTypeDNAT, network, externalIP, externalPort, internalIP, internalPort, protocol)
+	if err != nil {
+		return nil, err
+	}
+	return g.AddNatRule(*rule)
+}
+
+// AddSNAT translates traffic from internalIP leaving through the named
+// network's interface to externalIP. Ports and protocol are left unset, as
+// they do not apply to SNAT rules.
+func (g *EdgeGateway) AddSNAT(network, internalIP, externalIP string) (*Task, error) {
+	rule, err := g.newNatRule(NatRuleTypeSNAT, network, internalIP, "", externalIP, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return g.AddNatRule(*rule)
+}
+
+// EnsureDNAT adds a DNAT rule unless an identical rule already exists, in
+// which case the returned task is nil.
+func (g *EdgeGateway) EnsureDNAT(network, externalIP, externalPort, internalIP, internalPort, protocol string) (*Task, error) {
+	rule, err := g.newNatRule(NatRuleTypeDNAT, network, externalIP, externalPort, internalIP, internalPort, protocol)
+	if err != nil {
+		return nil, err
+	}
+	return g.ensureNatRule(rule)
+}
+
+// EnsureSNAT adds an SNAT rule unless an identical rule already exists, in
+// which case the returned task is nil.
+func (g *EdgeGateway) EnsureSNAT(network, internalIP, externalIP string) (*Task, error) {
+	rule, err := g.newNatRule(NatRuleTypeSNAT, network, internalIP, "", externalIP, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return g.ensureNatRule(rule)
+}
+
+func (g *EdgeGateway) ensureNatRule(rule *t.NatRule) (*Task, error) {
+	for _, existing := range g.NatRules() {
+		if natRulesEqual(&existing, rule) {
+			return nil, nil
+		}
+	}
+	return g.AddNatRule(*rule)
+}
+
+func (g *EdgeGateway) newNatRule(ruleType, network, originalIP, originalPort, translatedIP, translatedPort, protocol string) (*t.NatRule, error) {
+	iface, err := g.gatewayInterface(network)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t.NatRule{
+		RuleType:  ruleType,
+		IsEnabled: true,
+		GatewayNatRule: &t.GatewayNatRule{
+			Interface: &t.Reference{
+				Href: iface.Network.Href,
+				Type: iface.Network.Type,
+				Name: iface.Network.Name,
+			},
+			OriginalIP:     originalIP,
+			OriginalPort:   originalPort,
+			TranslatedIP:   translatedIP,
+			TranslatedPort: translatedPort,
+			Protocol:       protocol,
+		},
+	}, nil
+}
+
+func (g *EdgeGateway) natRulesOfType(ruleType string) []t.NatRule {
+	var rules []t.NatRule
+	for _, rule := range g.NatRules() {
+		if rule.RuleType == ruleType {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// natService selects the nat service for updateService.
+func natService(dst, src *t.EdgeGatewayServiceConfiguration) {
+	nat := t.NatService{IsEnabled: true}
+	if current := src.NatService; current != nil {
+		nat = *current
+		nat.NatRules = append([]t.NatRule(nil), current.NatRules...)
+	}
+	dst.NatService = &nat
+}
+
+func findNatRule(rules []t.NatRule, id string) int {
+	for i, rule := range rules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateNatRule(rule *t.NatRule) error {
+	if rule.RuleType != NatRuleTypeSNAT && rule.RuleType != NatRuleTypeDNAT {
+		return fmt.Errorf("invalid nat rule type %s", rule.RuleType)
+	}
+
+	r := rule.GatewayNatRule
+	if r == nil || r.Interface == nil || r.Interface.Href == "" {
+		return fmt.Errorf("%s rule must specify a gateway interface", rule.RuleType)
+	}
+
+	if r.OriginalIP == "" || r.TranslatedIP == "" {
+		return fmt.Errorf("%s rule must specify the original and translated ip", rule.RuleType)
+	}
+
+	return nil
+}
+
+func natRulesEqual(a, b *t.NatRule) bool {
+	if a.RuleType != b.RuleType || a.GatewayNatRule == nil || b.GatewayNatRule == nil {
+		return false
+	}
+
+	x, y := a.GatewayNatRule, b.GatewayNatRule
+
+	var xi, yi string
+	if x.Interface != nil {
+		xi = x.Interface.Href
+	}
+	if y.Interface != nil {
+		yi = y.Interface.Href
+	}
+
+	return xi == yi &&
+		x.OriginalIP == y.OriginalIP &&
+		x.TranslatedIP == y.TranslatedIP &&
+		natValue(x.OriginalPort) == natValue(y.OriginalPort) &&
+		natValue(x.TranslatedPort) == natValue(y.TranslatedPort) &&
+		natValue(x.Protocol) == natValue(y.Protocol) &&
+		natValue(x.IcmpSubType) == natValue(y.IcmpSubType)
+}
+
+// natValue normalises optional ports and protocols, which default to any.
+func natValue(v string) string {
+	if v == "" {
+		return "any"
+	}
+	return strings.ToLower(v)
+}
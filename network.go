@@ -5,12 +5,29 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	t "git.r3labs.io/libraries/go-vcloud/types"
 )
 
 const (
-	orgNetworkType = "application/vnd.vmware.vcloud.orgVdcNetwork+xml"
+	orgNetworkType      = "application/vnd.vmware.vcloud.orgVdcNetwork+xml"
+	adminNetworkType    = "application/vnd.vmware.admin.network+xml"
+	externalNetworkType = "application/vnd.vmware.admin.vmwexternalnet+xml"
+)
+
+// Network statuses
+const (
+	NetworkStatusFailed  = "-1"
+	NetworkStatusPending = "0"
+	NetworkStatusReady   = "1"
+)
+
+// Network fence modes
+const (
+	FenceModeIsolated  = "isolated"
+	FenceModeNatRouted = "natRouted"
+	FenceModeBridged   = "bridged"
 )
 
 // Network ...
@@ -24,15 +41,20 @@ type Network struct {
 	Href          string     `xml:"href,attr,omitempty"`
 	ID            string     `xml:"id,attr,omitempty"`
 	Status        string     `xml:"status,attr,omitempty"`
+	Links         []t.Link   `xml:"Link"`
 	Description   string     `xml:"Description,value"`
 	Tasks         *Tasks     `xml:"Tasks"`
 	Configuration struct {
-		IPScopes      t.IPScopes `xml:"IpScopes"`
-		FenceMode     string     `xml:"FenceMode"`
-		RetainNetInfo bool       `xml:"RetainNetInfoAcrossDeployments"`
+		IPScopes             t.IPScopes   `xml:"IpScopes"`
+		ParentNetwork        *t.Reference `xml:"ParentNetwork,omitempty"`
+		FenceMode            string       `xml:"FenceMode"`
+		RetainNetInfo        bool         `xml:"RetainNetInfoAcrossDeployments"`
+		SubInterface         *bool        `xml:"SubInterface,omitempty"`
+		DistributedInterface *bool        `xml:"DistributedInterface,omitempty"`
 	} `xml:"Configuration"`
-	EdgeGateway *t.NetworkGateway `xml:"EdgeGateway,omitempty"`
-	IsShared    bool              `xml:"IsShared,value,omitempty"`
+	EdgeGateway   *t.NetworkGateway       `xml:"EdgeGateway,omitempty"`
+	ServiceConfig *t.NetworkServiceConfig `xml:"ServiceConfig,omitempty"`
+	IsShared      bool                    `xml:"IsShared,value,omitempty"`
 }
 
 // NewNetwork ...
@@ -65,44 +87,77 @@ func parseNetwork(d *[]byte) *Network {
 // Reload ...
 func (n *Network) Reload() error {
 	nw, err := NewNetwork(n.Connector, n.Href)
-	n = nw
-	return err
+	if err != nil {
+		return err
+	}
+	*n = *nw
+	return nil
 }
 
-// Update ...
+// Update validates the network's ip scopes and submits the network. The
+// network should be reloaded once the returned task has completed.
 func (n *Network) Update() (*Task, error) {
-	data, err := xml.Marshal(n)
+	err := n.ValidateIPScopes()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := n.Connector.Put(n.getAdminHref(), data, orgNetworkType)
+	err = n.checkIPScopesVersion(n.Connector)
 	if err != nil {
 		return nil, err
 	}
 
-	tdata, err := ParseResponse(resp)
+	data, err := xml.Marshal(n)
 	if err != nil {
 		return nil, err
 	}
 
-	n.Reload()
-	task := ParseTask(tdata)
+	return n.Connector.PutTask(n.getAdminHref(), data, orgNetworkType)
+}
 
-	return task, nil
+// WaitReady polls the network until it is ready, returning an error if it
+// could not be created.
+func (n *Network) WaitReady() error {
+	o := defaultWaitOptions(nil)
+	ctx := n.Connector.Context()
+	interval := o.Interval
+
+	for {
+		err := n.Reload()
+		if err != nil {
+			return err
+		}
+
+		switch n.Status {
+		case NetworkStatusReady:
+			return nil
+		case NetworkStatusFailed:
+			return fmt.Errorf("network %s could not be created", n.Name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval = time.Duration(float64(interval) * o.Multiplier)
+		if interval > o.MaxInterval {
+			interval = o.MaxInterval
+		}
+	}
 }
 
 // Delete ...
-func (n *Network) Delete() error {
-	err := n.Connector.Delete(n.getAdminHref())
-	if err != nil {
-		fmt.Println(err)
-	}
-	return nil
+func (n *Network) Delete() (*Task, error) {
+	return n.Connector.DeleteTask(n.getAdminHref())
 }
 
 // GetTasks ...
 func (n *Network) GetTasks() []Task {
+	if n.Tasks == nil {
+		return nil
+	}
 	for i := 0; i < len(n.Tasks.Task); i++ {
 		n.Tasks.Task[i].Connector = n.Connector
 	}
@@ -115,10 +170,14 @@ func (n *Network) SetIsInherited(inherited bool) {
 	n.Configuration.IPScopes.IPScope[0].IsInherited = inherited
 }
 
-// Netmask ...
+// Netmask returns the netmask of the first ip scope, or an empty string if
+// the network has no ip scope.
 func (n *Network) Netmask() string {
-	n.configureIPScope()
-	return n.Configuration.IPScopes.IPScope[0].Netmask
+	scopes := n.IPScopes()
+	if len(scopes) < 1 {
+		return ""
+	}
+	return scopes[0].Netmask
 }
 
 // SetNetmask ...
@@ -127,10 +186,14 @@ func (n *Network) SetNetmask(netmask string) {
 	n.Configuration.IPScopes.IPScope[0].Netmask = netmask
 }
 
-// Gateway ...
+// Gateway returns the gateway of the first ip scope, or an empty string if
+// the network has no ip scope.
 func (n *Network) Gateway() string {
-	n.configureIPScope()
-	return n.Configuration.IPScopes.IPScope[0].Gateway
+	scopes := n.IPScopes()
+	if len(scopes) < 1 {
+		return ""
+	}
+	return scopes[0].Gateway
 }
 
 // SetGateway ...
@@ -165,13 +228,14 @@ func (n *Network) SetDNS2(ns string) {
 	n.Configuration.IPScopes.IPScope[0].DNS2 = ns
 }
 
-// SetStartAddress ...
+// SetStartAddress sets the start of the first range of the first ip scope.
+// Use AddIPRange and UpdateIPRange on networks with several ranges.
 func (n *Network) SetStartAddress(start string) {
 	n.configureIPRange()
 	n.Configuration.IPScopes.IPScope[0].IPRanges.IPRange[0].StartAddress = start
 }
 
-// SetEndAddress ...
+// SetEndAddress sets the end of the first range of the first ip scope.
 func (n *Network) SetEndAddress(end string) {
 	n.configureIPRange()
 	n.Configuration.IPScopes.IPScope[0].IPRanges.IPRange[0].EndAddress = end
@@ -179,13 +243,11 @@ func (n *Network) SetEndAddress(end string) {
 
 // SetRetainNetInfo ...
 func (n *Network) SetRetainNetInfo(retained bool) {
-	n.configureIPRange()
 	n.Configuration.RetainNetInfo = retained
 }
 
-// SetFenceMode ...
+// SetFenceMode sets one of the FenceMode network types.
 func (n *Network) SetFenceMode(mode string) {
-	n.configureIPRange()
 	n.Configuration.FenceMode = mode
 }
 
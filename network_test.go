@@ -0,0 +1,477 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	types "git.r3labs.io/libraries/go-vcloud/types"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func networkHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	status := NetworkStatusReady
+	if ps.ByName("id") == "failed" {
+		status = NetworkStatusFailed
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<OrgVdcNetwork xmlns="http://www.vmware.com/vcloud/v1.5" status="%s" name="internal" type="application/vnd.vmware.vcloud.orgVdcNetwork+xml" href="https://%s/api/network/%s">
+    <Link rel="down" type="application/vnd.vmware.vcloud.allocatedNetworkAddress+xml" href="https://%s/api/network/%s/allocatedAddresses"/>
+    <Description>internal network</Description>
+    <Configuration>
+        <IpScopes>
+            <IpScope>
+                <IsInherited>false</IsInherited>
+                <Gateway>10.0.0.1</Gateway>
+                <Netmask>255.255.255.0</Netmask>
+                <IsEnabled>true</IsEnabled>
+            </IpScope>
+        </IpScopes>
+        <FenceMode>isolated</FenceMode>
+        <RetainNetInfoAcrossDeployments>false</RetainNetInfoAcrossDeployments>
+    </Configuration>
+    <IsShared>false</IsShared>
+</OrgVdcNetwork>`, status, r.Host, ps.ByName("id"), r.Host, ps.ByName("id"))
+}
+
+// echoHandler echoes the created entity back, as vCloud does along with
+// its creation task.
+func echoHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(*parseRequest(r))
+}
+
+// networkCreationHandler returns the posted network with an href named after
+// it and a running creation task.
+func networkCreationHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	n := Network{}
+	_ = xml.Unmarshal(*parseRequest(r), &n)
+	n.Href = fmt.Sprintf("https://%s/api/network/%s", r.Host, n.Name)
+	n.Status = NetworkStatusPending
+	n.Tasks = &Tasks{Task: []Task{
+		{Name: "task", Status: TaskStatusRunning, OperationName: "networkCreateOrgVdcNetwork", Href: fmt.Sprintf("https://%s/api/task/success", r.Host)},
+	}}
+
+	data, _ := xml.Marshal(n)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+func allocatedAddressesHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<AllocatedIpAddresses xmlns="http://www.vmware.com/vcloud/v1.5" type="application/vnd.vmware.vcloud.allocatedNetworkAddress+xml" href="https://%s/api/network/%s/allocatedAddresses">
+    <IpAddress allocationType="vsmAllocated" isDeployed="true">
+        <IpAddress>10.0.0.1</IpAddress>
+    </IpAddress>
+    <IpAddress allocationType="vmAllocated" isDeployed="true">
+        <Link rel="down" type="application/vnd.vmware.vcloud.vm+xml" name="web-1" href="https://%s/api/vApp/vm-1"/>
+        <Link rel="down" type="application/vnd.vmware.vcloud.vApp+xml" name="web" href="https://%s/api/vApp/vapp-1"/>
+        <IpAddress>10.0.0.10</IpAddress>
+    </IpAddress>
+    <IpAddress allocationType="vmAllocated" isDeployed="false">
+        <IpAddress>10.0.0.11</IpAddress>
+    </IpAddress>
+</AllocatedIpAddresses>`, r.Host, ps.ByName("id"), r.Host, r.Host)
+}
+
+func TestNetworkIPScopes(t *testing.T) {
+	Convey("Given a network without an ip scope", t, func() {
+		n := &Network{Name: "direct"}
+
+		Convey("Its gateway and netmask should be empty", func() {
+			So(n.Gateway(), ShouldBeEmpty)
+			So(n.Netmask(), ShouldBeEmpty)
+		})
+
+		Convey("Reading them should not add a scope", func() {
+			n.Gateway()
+			n.Netmask()
+			So(n.IPScopes(), ShouldBeEmpty)
+			So(n.Configuration.IPScopes.IPScope, ShouldBeNil)
+		})
+
+		Convey("No ip scopes should be marshalled", func() {
+			data, err := xml.Marshal(n)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldNotContainSubstring, "IpScopes")
+		})
+	})
+
+	Convey("Given a network with a single ip scope", t, func() {
+		n := &Network{Name: "internal"}
+		err := n.AddIPScope(types.IPScope{
+			Gateway: "10.0.0.1",
+			Netmask: "255.255.255.0",
+			IPRanges: types.IPRanges{IPRange: []types.IPRange{
+				{StartAddress: "10.0.0.10", EndAddress: "10.0.0.49"},
+			}},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When adding a second range", func() {
+			err := n.AddIPRange("10.0.0.1", "10.0.0.100", "10.0.0.149")
+			Convey("Both ranges should be kept", func() {
+				So(err, ShouldBeNil)
+				ranges, _ := n.IPRanges("10.0.0.1")
+				So(ranges, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When adding an overlapping range", func() {
+			err := n.AddIPRange("10.0.0.1", "10.0.0.40", "10.0.0.60")
+			Convey("The range should be rejected", func() {
+				So(err, ShouldNotBeNil)
+				ranges, _ := n.IPRanges("10.0.0.1")
+				So(ranges, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When adding a range outside the subnet", func() {
+			So(n.AddIPRange("10.0.0.1", "10.0.1.10", "10.0.1.20"), ShouldNotBeNil)
+		})
+
+		Convey("When adding a range including the gateway", func() {
+			So(n.AddIPRange("10.0.0.1", "10.0.0.1", "10.0.0.5"), ShouldNotBeNil)
+		})
+
+		Convey("When editing and removing a range", func() {
+			So(n.UpdateIPRange("10.0.0.1", "10.0.0.10", "10.0.0.20", "10.0.0.29"), ShouldBeNil)
+			ranges, _ := n.IPRanges("10.0.0.1")
+			So(ranges[0].StartAddress, ShouldEqual, "10.0.0.20")
+			So(n.RemoveIPRange("10.0.0.1", "10.0.0.20"), ShouldBeNil)
+			ranges, _ = n.IPRanges("10.0.0.1")
+			So(ranges, ShouldHaveLength, 0)
+		})
+
+		Convey("When adding an ipv6 scope", func() {
+			err := n.AddIPScope(types.IPScope{
+				Gateway:            "2001:db8::1",
+				SubnetPrefixLength: 64,
+				DNSSuffix:          "example.com",
+				IPRanges: types.IPRanges{IPRange: []types.IPRange{
+					{StartAddress: "2001:db8::10", EndAddress: "2001:db8::ff"},
+				}},
+			})
+			Convey("Both scopes should be kept", func() {
+				So(err, ShouldBeNil)
+				So(n.IPScopes(), ShouldHaveLength, 2)
+				So(n.ValidateIPScopes(), ShouldBeNil)
+			})
+		})
+
+		Convey("When adding an overlapping scope", func() {
+			err := n.AddIPScope(types.IPScope{Gateway: "10.0.0.254", Netmask: "255.255.0.0"})
+			Convey("The scope should be rejected", func() {
+				So(err, ShouldNotBeNil)
+				So(n.IPScopes(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When removing the scope", func() {
+			So(n.RemoveIPScope("10.0.0.1"), ShouldBeNil)
+			So(n.IPScopes(), ShouldHaveLength, 0)
+		})
+	})
+}
+
+func TestNetworkAllocatedAddresses(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a network with allocated addresses", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		n := &Network{Connector: c, Name: "internal", Href: fmt.Sprintf("https://%s/api/network/1", tsurl.Host)}
+		_ = n.AddIPScope(types.IPScope{
+			Gateway: "10.0.0.1",
+			Netmask: "255.255.255.0",
+			IPRanges: types.IPRanges{IPRange: []types.IPRange{
+				{StartAddress: "10.0.0.10", EndAddress: "10.0.0.14"},
+			}},
+		})
+
+		Convey("When listing the allocated addresses", func() {
+			addresses, err := n.AllocatedAddresses()
+			Convey("Typed entries should be returned", func() {
+				So(err, ShouldBeNil)
+				So(addresses, ShouldHaveLength, 3)
+				So(addresses[0].AllocationType, ShouldEqual, AllocationTypeVSM)
+				So(addresses[1].IPAddress, ShouldEqual, "10.0.0.10")
+				So(addresses[1].VMHref, ShouldEndWith, "/api/vApp/vm-1")
+				So(addresses[1].VAppHref, ShouldEndWith, "/api/vApp/vapp-1")
+				So(addresses[2].Deployed, ShouldBeFalse)
+			})
+		})
+
+		Convey("When listing the free addresses", func() {
+			free, err := n.FreeAddresses()
+			Convey("Allocated addresses should be excluded", func() {
+				So(err, ShouldBeNil)
+				So(free, ShouldResemble, []string{"10.0.0.12", "10.0.0.13", "10.0.0.14"})
+			})
+		})
+
+		Convey("When getting the next available ip", func() {
+			ip, err := n.NextAvailableIP()
+			Convey("The first free address should be returned", func() {
+				So(err, ShouldBeNil)
+				So(ip, ShouldEqual, "10.0.0.12")
+			})
+		})
+	})
+}
+
+func TestNetworkReloadAndDelete(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a stale network", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		n := &Network{Connector: c, Name: "stale", Href: fmt.Sprintf("https://%s/api/network/1", tsurl.Host)}
+
+		Convey("When reloading the network", func() {
+			err := n.Reload()
+			Convey("It should be updated in place", func() {
+				So(err, ShouldBeNil)
+				So(n.Name, ShouldEqual, "internal")
+				So(n.Status, ShouldEqual, "1")
+				So(n.Gateway(), ShouldEqual, "10.0.0.1")
+				So(n.Links, ShouldHaveLength, 1)
+				So(n.Connector, ShouldEqual, c)
+				So(n.GetTasks(), ShouldBeNil)
+			})
+		})
+
+		Convey("When updating the network", func() {
+			So(n.Reload(), ShouldBeNil)
+			n.SetDNS1("10.0.0.2")
+			task, err := n.Update()
+			req := lastRequest()
+			Convey("The network should be put to its admin href", func() {
+				So(err, ShouldBeNil)
+				So(req.Method, ShouldEqual, "PUT")
+				So(req.Path, ShouldEqual, "/api/admin/network/1")
+				So(req.ContentType, ShouldEqual, orgNetworkType)
+				So(string(req.Body), ShouldContainSubstring, "<Dns1>10.0.0.2</Dns1>")
+			})
+			Convey("A task that can be waited on should be returned", func() {
+				So(task.Connector, ShouldEqual, c)
+				So(task.Wait(), ShouldBeNil)
+			})
+		})
+
+		Convey("When updating a bridged network", func() {
+			direct := &Network{Connector: c, Name: "direct", Href: n.Href}
+			direct.SetFenceMode(FenceModeBridged)
+			direct.SetRetainNetInfo(true)
+			task, err := direct.Update()
+			req := lastRequest()
+			Convey("No ip scope should be added", func() {
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+				So(direct.IPScopes(), ShouldHaveLength, 0)
+				So(string(req.Body), ShouldNotContainSubstring, "IpScope")
+				So(string(req.Body), ShouldContainSubstring, "<RetainNetInfoAcrossDeployments>true</RetainNetInfoAcrossDeployments>")
+			})
+		})
+
+		Convey("When updating an ipv6 scope", func() {
+			So(n.Reload(), ShouldBeNil)
+			So(n.AddIPScope(types.IPScope{Gateway: "2001:db8::1", SubnetPrefixLength: 64}), ShouldBeNil)
+
+			Convey("An older api version should be rejected", func() {
+				task, err := n.Update()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, subnetPrefixLengthVersion)
+				So(task, ShouldBeNil)
+			})
+			Convey("A newer api version should be accepted", func() {
+				c.session.version = subnetPrefixLengthVersion
+				task, err := n.Update()
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+				So(string(lastRequest().Body), ShouldContainSubstring, "<SubnetPrefixLength>64</SubnetPrefixLength>")
+			})
+		})
+
+		Convey("When the update is invalid", func() {
+			So(n.Reload(), ShouldBeNil)
+			n.SetNetmask("255.255.255.256")
+			task, err := n.Update()
+			So(err, ShouldNotBeNil)
+			So(task, ShouldBeNil)
+		})
+
+		Convey("When deleting the network", func() {
+			task, err := n.Delete()
+			Convey("The delete task should be returned", func() {
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+				So(task.Status, ShouldEqual, TaskStatusQueued)
+			})
+		})
+	})
+}
+
+func TestNetworkCreation(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a datacenter", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		d := &Datacenter{Connector: c, Links: []types.Link{
+			{Rel: "add", Type: orgNetworkType, Href: fmt.Sprintf("https://%s/api/admin/vdc/1/networks", tsurl.Host)},
+		}}
+		gw := &EdgeGateway{Name: "gateway", Href: "https://vcloud.example.com/api/admin/edgeGateway/1"}
+
+		scope := types.IPScope{
+			Gateway: "10.0.0.1",
+			Netmask: "255.255.255.0",
+			IPRanges: types.IPRanges{IPRange: []types.IPRange{
+				{StartAddress: "10.0.0.10", EndAddress: "10.0.0.99"},
+			}},
+		}
+
+		Convey("When creating a network and waiting for it", func() {
+			n, err := d.CreateRoutedNetwork("routed", gw, scope, nil)
+			So(err, ShouldBeNil)
+			created, err := d.CreateNetworkAndWait(n)
+			Convey("The network should be returned once it is ready", func() {
+				So(err, ShouldBeNil)
+				So(created.Status, ShouldEqual, NetworkStatusReady)
+				So(created.Href, ShouldEqual, fmt.Sprintf("https://%s/api/network/routed", tsurl.Host))
+				So(created.Connector, ShouldEqual, c)
+			})
+		})
+
+		Convey("When the network can not be created", func() {
+			n, err := d.CreateRoutedNetwork("failed", gw, scope, nil)
+			So(err, ShouldBeNil)
+			created, err := d.CreateNetworkAndWait(n)
+			Convey("An error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(created, ShouldBeNil)
+			})
+		})
+
+		Convey("When creating a distributed network on an older api version", func() {
+			_, err := d.CreateRoutedNetwork("routed", gw, scope, &NetworkOptions{Distributed: true})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "27.0")
+		})
+
+		Convey("When creating a subinterface on an older api version", func() {
+			_, err := d.CreateRoutedNetwork("routed", gw, scope, &NetworkOptions{SubInterface: true})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "5.6")
+		})
+
+		Convey("When creating a routed network", func() {
+			c.session.version = "27.0"
+			n, err := d.CreateRoutedNetwork("routed", gw, scope, &NetworkOptions{Distributed: true})
+			Convey("It should be connected to the edge gateway", func() {
+				So(err, ShouldBeNil)
+				So(n.Configuration.FenceMode, ShouldEqual, FenceModeNatRouted)
+				So(n.EdgeGateway.Href, ShouldEqual, gw.Href)
+				So(*n.Configuration.DistributedInterface, ShouldBeTrue)
+				So(n.Configuration.SubInterface, ShouldBeNil)
+			})
+		})
+
+		Convey("When creating a routed network without a gateway", func() {
+			_, err := d.CreateRoutedNetwork("routed", nil, scope, nil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When creating a distributed subinterface", func() {
+			_, err := d.CreateRoutedNetwork("routed", gw, scope, &NetworkOptions{Distributed: true, SubInterface: true})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When creating an isolated network with dhcp", func() {
+			dhcp := &types.DhcpService{IPRange: &types.IPRange{StartAddress: "10.0.0.100", EndAddress: "10.0.0.199"}}
+			n, err := d.CreateIsolatedNetwork("isolated", scope, dhcp, nil)
+			Convey("The dhcp service should be configured", func() {
+				So(err, ShouldBeNil)
+				So(n.Configuration.FenceMode, ShouldEqual, FenceModeIsolated)
+				So(n.ServiceConfig.DhcpService.IsEnabled, ShouldBeTrue)
+				So(n.ServiceConfig.DhcpService.IPRange.StartAddress, ShouldEqual, "10.0.0.100")
+				So(n.ServiceConfig.DhcpService.MaxLeaseTime, ShouldEqual, 7200)
+			})
+			Convey("The caller's dhcp service should be left unchanged", func() {
+				So(dhcp.IsEnabled, ShouldBeFalse)
+				So(dhcp.MaxLeaseTime, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When creating an isolated network with an overlapping dhcp range", func() {
+			dhcp := &types.DhcpService{IsEnabled: true, IPRange: &types.IPRange{StartAddress: "10.0.0.50", EndAddress: "10.0.0.150"}}
+			_, err := d.CreateIsolatedNetwork("isolated", scope, dhcp, nil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When creating a direct network", func() {
+			n, err := d.CreateDirectNetwork("direct", types.Reference{Href: "https://vcloud.example.com/api/admin/network/ext"}, &NetworkOptions{Shared: true})
+			Convey("It should be bridged onto its parent", func() {
+				So(err, ShouldBeNil)
+				So(n.Configuration.FenceMode, ShouldEqual, FenceModeBridged)
+				So(n.Configuration.ParentNetwork.Href, ShouldEqual, "https://vcloud.example.com/api/admin/network/ext")
+				So(n.Configuration.IPScopes.IPScope, ShouldBeNil)
+				So(n.IsShared, ShouldBeTrue)
+			})
+		})
+
+		Convey("When creating a direct network without a parent", func() {
+			_, err := d.CreateDirectNetwork("direct", types.Reference{}, nil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,252 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+const networkPoolType = "application/vnd.vmware.admin.networkPool+xml"
+
+// Network pool types
+const (
+	NetworkPoolTypeVlan      = "VlanPoolType"
+	NetworkPoolTypeVxlan     = "VxlanPoolType"
+	NetworkPoolTypePortGroup = "PortGroupPoolType"
+)
+
+// NetworkPool is a provider pool of vlans, vxlans or port groups used to
+// back org vdc networks, available to system administrators through the
+// extension api.
+type NetworkPool struct {
+	Connector   *Connector `xml:"-"`
+	XMLName     xml.Name   `xml:"http://www.vmware.com/vcloud/extension/v1.5 VMWNetworkPool"`
+	XMLNS       string     `xml:"xmlns:vmext,attr,omitempty"`
+	PoolType    string     `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+	Type        string     `xml:"type,attr,omitempty"`
+	Name        string     `xml:"name,attr"`
+	Href        string     `xml:"href,attr,omitempty"`
+	ID          string     `xml:"id,attr,omitempty"`
+	Status      string     `xml:"status,attr,omitempty"`
+	Links       []t.Link   `xml:"http://www.vmware.com/vcloud/v1.5 Link"`
+	Description string     `xml:"http://www.vmware.com/vcloud/v1.5 Description,omitempty"`
+	Tasks       *Tasks     `xml:"http://www.vmware.com/vcloud/v1.5 Tasks,omitempty"`
+	PoolUsed    int        `xml:"PoolUsed,omitempty"`
+	PoolTotal   int        `xml:"PoolTotal,omitempty"`
+	// vlan pools
+	VlanRanges []t.VlanRange `xml:"VlanRange"`
+	// vlan and vxlan pools
+	VimSwitchRef *t.VimObjectRef `xml:"VimSwitchRef,omitempty"`
+	// port group pools
+	PortGroupRefs *t.VimObjectRefs `xml:"PortGroupRefs,omitempty"`
+	VimServer     *t.Reference     `xml:"VimServer,omitempty"`
+}
+
+// ListNetworkPools ...
+func ListNetworkPools(c *Connector) ([]t.Reference, error) {
+	resp, err := c.Get(extensionURL(c, "networkPoolReferences"))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs t.VMWNetworkPoolReferences
+	err = xml.Unmarshal(*data, &refs)
+	if err != nil {
+		return nil, err
+	}
+
+	return refs.References, nil
+}
+
+// FindNetworkPool returns the named network pool, or a not found error if
+// there is no such pool.
+func FindNetworkPool(c *Connector, name string) (*NetworkPool, error) {
+	refs, err := ListNetworkPools(c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if ref.Name == name {
+			return NewNetworkPool(c, ref.Href)
+		}
+	}
+
+	return nil, newNotFoundError(fmt.Sprintf("network pool %s not found", name))
+}
+
+// NewNetworkPool ...
+func NewNetworkPool(c *Connector, href string) (*NetworkPool, error) {
+	resp, err := c.Get(href)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	p := NetworkPool{}
+	err = xml.Unmarshal(*data, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Connector = c
+
+	return &p, nil
+}
+
+// CreateNetworkPool creates a vlan or port group network pool. Vxlan pools
+// are created by vcloud with each provider vdc, so can not be created here.
+// The returned pool's tasks track its creation.
+func CreateNetworkPool(c *Connector, p *NetworkPool) (*NetworkPool, error) {
+	if p.Kind() == NetworkPoolTypeVxlan {
+		return nil, fmt.Errorf("vxlan network pool %s can not be created, vcloud creates vxlan pools for provider vdcs", p.Name)
+	}
+
+	err := p.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := p.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Post(extensionURL(c, "networkPools"), data, networkPoolType)
+	if err != nil {
+		return nil, err
+	}
+
+	rdata, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	created := NetworkPool{}
+	err = xml.Unmarshal(*rdata, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Connector = c
+
+	return &created, nil
+}
+
+// Kind returns the pool's NetworkPoolType.
+func (p *NetworkPool) Kind() string {
+	return p.PoolType[strings.LastIndex(p.PoolType, ":")+1:]
+}
+
+// Usage returns the number of vlans, vxlans or port groups in use and in
+// total in the pool.
+func (p *NetworkPool) Usage() (int, int) {
+	return p.PoolUsed, p.PoolTotal
+}
+
+// Reload ...
+func (p *NetworkPool) Reload() error {
+	updated, err := NewNetworkPool(p.Connector, p.Href)
+	if err != nil {
+		return err
+	}
+	*p = *updated
+	return nil
+}
+
+// Update ...
+func (p *NetworkPool) Update() (*Task, error) {
+	err := p.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := p.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Connector.PutTask(p.Href, data, networkPoolType)
+}
+
+// Delete ...
+func (p *NetworkPool) Delete() (*Task, error) {
+	return p.Connector.DeleteTask(p.Href)
+}
+
+// GetTasks ...
+func (p *NetworkPool) GetTasks() []Task {
+	if p.Tasks == nil {
+		return nil
+	}
+	for i := 0; i < len(p.Tasks.Task); i++ {
+		p.Tasks.Task[i].Connector = p.Connector
+	}
+	return p.Tasks.Task
+}
+
+// marshal encodes the pool with its type qualified by the extension
+// namespace, as the api requires.
+func (p *NetworkPool) marshal() ([]byte, error) {
+	pool := *p
+	pool.XMLNS = extensionNamespace
+	pool.PoolType = "vmext:" + p.Kind()
+	pool.PoolUsed = 0
+	pool.PoolTotal = 0
+	return xml.Marshal(pool)
+}
+
+func (p *NetworkPool) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("network pool name must not be empty")
+	}
+
+	switch p.Kind() {
+	case NetworkPoolTypeVlan:
+		if p.VimSwitchRef == nil || p.VimSwitchRef.MoRef == "" {
+			return fmt.Errorf("vlan network pool %s must reference a vcenter switch", p.Name)
+		}
+
+		if len(p.VlanRanges) < 1 {
+			return fmt.Errorf("vlan network pool %s must have a vlan range", p.Name)
+		}
+
+		for i, r := range p.VlanRanges {
+			if r.Start < 1 || r.End > 4094 || r.Start > r.End {
+				return fmt.Errorf("vlan network pool %s: invalid vlan range %d-%d", p.Name, r.Start, r.End)
+			}
+
+			for _, o := range p.VlanRanges[:i] {
+				if r.Start <= o.End && o.Start <= r.End {
+					return fmt.Errorf("vlan network pool %s: vlan range %d-%d overlaps %d-%d", p.Name, r.Start, r.End, o.Start, o.End)
+				}
+			}
+		}
+	case NetworkPoolTypePortGroup:
+		if p.VimServer == nil || p.VimServer.Href == "" {
+			return fmt.Errorf("port group network pool %s must reference a vcenter server", p.Name)
+		}
+
+		if p.PortGroupRefs == nil || len(p.PortGroupRefs.VimObjectRef) < 1 {
+			return fmt.Errorf("port group network pool %s must have a port group", p.Name)
+		}
+	case NetworkPoolTypeVxlan:
+		// vxlan pools are managed by vcloud, only their name and
+		// description can be changed.
+	default:
+		return fmt.Errorf("network pool %s: invalid pool type %s", p.Name, p.PoolType)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package vcloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	types "git.r3labs.io/libraries/go-vcloud/types"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func networkPoolReferencesHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<vmext:VMWNetworkPoolReferences xmlns:vmext="http://www.vmware.com/vcloud/extension/v1.5">
+    <vmext:NetworkPoolReference type="application/vnd.vmware.admin.networkPool+xml" name="vxlan" href="https://%s/api/admin/extension/networkPool/1"/>
+    <vmext:NetworkPoolReference type="application/vnd.vmware.admin.networkPool+xml" name="vlan" href="https://%s/api/admin/extension/networkPool/2"/>
+</vmext:VMWNetworkPoolReferences>`, r.Host, r.Host)
+}
+
+func networkPoolHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<vmext:VMWNetworkPool xmlns:vmext="http://www.vmware.com/vcloud/extension/v1.5" xmlns:vcloud="http://www.vmware.com/vcloud/v1.5" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="vmext:VlanPoolType" name="vlan" type="application/vnd.vmware.admin.networkPool+xml" href="https://%s/api/admin/extension/networkPool/%s">
+    <vcloud:Description>tenant vlans</vcloud:Description>
+    <vmext:PoolUsed>3</vmext:PoolUsed>
+    <vmext:PoolTotal>100</vmext:PoolTotal>
+    <vmext:VlanRange>
+        <vmext:Start>100</vmext:Start>
+        <vmext:End>199</vmext:End>
+    </vmext:VlanRange>
+    <vmext:VimSwitchRef>
+        <vmext:VimServerRef type="application/vnd.vmware.admin.vmwvirtualcenter+xml" name="vc" href="https://%s/api/admin/extension/vimServer/1"/>
+        <vmext:MoRef>dvs-21</vmext:MoRef>
+        <vmext:VimObjectType>DV_SWITCH</vmext:VimObjectType>
+    </vmext:VimSwitchRef>
+</vmext:VMWNetworkPool>`, r.Host, ps.ByName("id"), r.Host)
+}
+
+func TestNetworkPools(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a system administrator connector", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		pool := &NetworkPool{
+			Name:     "vlan-2",
+			PoolType: NetworkPoolTypeVlan,
+			VimSwitchRef: &types.VimObjectRef{
+				VimServerRef:  types.Reference{Href: "https://vcloud.example.com/api/admin/extension/vimServer/1"},
+				MoRef:         "dvs-21",
+				VimObjectType: "DV_SWITCH",
+			},
+			VlanRanges: []types.VlanRange{{Start: 100, End: 199}},
+		}
+
+		Convey("When listing network pools", func() {
+			refs, err := ListNetworkPools(c)
+			So(err, ShouldBeNil)
+			So(refs, ShouldHaveLength, 2)
+			So(refs[1].Name, ShouldEqual, "vlan")
+		})
+
+		Convey("When finding a network pool", func() {
+			p, err := FindNetworkPool(c, "vlan")
+			So(err, ShouldBeNil)
+
+			Convey("Its kind and usage should be reported", func() {
+				So(p.Kind(), ShouldEqual, NetworkPoolTypeVlan)
+				used, total := p.Usage()
+				So(used, ShouldEqual, 3)
+				So(total, ShouldEqual, 100)
+				So(p.VlanRanges, ShouldHaveLength, 1)
+			})
+
+			Convey("When updating it", func() {
+				p.Description = "all vlans"
+				task, err := p.Update()
+				req := lastRequest()
+
+				Convey("The pool should be put without its usage", func() {
+					So(err, ShouldBeNil)
+					So(task, ShouldNotBeNil)
+					So(req.Method, ShouldEqual, "PUT")
+					So(req.Path, ShouldEqual, "/api/admin/extension/networkPool/2")
+					So(req.ContentType, ShouldEqual, networkPoolType)
+					So(string(req.Body), ShouldContainSubstring, `:type="vmext:VlanPoolType"`)
+					So(string(req.Body), ShouldContainSubstring, "all vlans")
+					So(string(req.Body), ShouldNotContainSubstring, "PoolUsed")
+				})
+			})
+
+			Convey("When deleting it", func() {
+				task, err := p.Delete()
+				req := lastRequest()
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+				So(req.Method, ShouldEqual, "DELETE")
+				So(req.Path, ShouldEqual, "/api/admin/extension/networkPool/2")
+			})
+
+			Convey("When reloading it", func() {
+				p.Description = "changed"
+				So(p.Reload(), ShouldBeNil)
+				So(p.Description, ShouldEqual, "tenant vlans")
+				So(p.Connector, ShouldEqual, c)
+			})
+		})
+
+		Convey("When creating a vlan pool", func() {
+			created, err := CreateNetworkPool(c, pool)
+			Convey("The pool type should round trip", func() {
+				So(err, ShouldBeNil)
+				So(created.Kind(), ShouldEqual, NetworkPoolTypeVlan)
+				So(created.VlanRanges, ShouldHaveLength, 1)
+				So(created.VimSwitchRef.MoRef, ShouldEqual, "dvs-21")
+			})
+		})
+
+		Convey("When creating a pool with overlapping vlan ranges", func() {
+			pool.VlanRanges = append(pool.VlanRanges, types.VlanRange{Start: 150, End: 250})
+			_, err := CreateNetworkPool(c, pool)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When creating a port group pool without port groups", func() {
+			pool.PoolType = NetworkPoolTypePortGroup
+			pool.VimServer = &types.Reference{Href: "https://vcloud.example.com/api/admin/extension/vimServer/1"}
+			_, err := CreateNetworkPool(c, pool)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When creating a vxlan pool", func() {
+			pool.PoolType = NetworkPoolTypeVxlan
+			_, err := CreateNetworkPool(c, pool)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "vxlan")
+		})
+	})
+}
@@ -40,7 +40,11 @@ func OrgList(c *Connector) (*[]t.OrgListOrg, error) {
 
 // NewOrg ...
 func NewOrg(c *Connector, name string) (*Org, error) {
-	href := findOrgHref(c, name)
+	href, err := findOrgHref(c, name)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := c.Get(href)
 	if err != nil {
 		return nil, err
@@ -105,14 +109,18 @@ func (o *Org) Catalogs() []t.Link {
 	return catalogs
 }
 
-func findOrgHref(c *Connector, name string) string {
-	orgs, _ := OrgList(c)
+func findOrgHref(c *Connector, name string) (string, error) {
+	orgs, err := OrgList(c)
+	if err != nil {
+		return "", err
+	}
+
 	for _, org := range *orgs {
 		if org.Name == name {
-			return org.Href
+			return org.Href, nil
 		}
 	}
-	return ""
+	return "", fmt.Errorf("could not find org %s", name)
 }
 
 func (o *Org) findLinks(xt string) []t.Link {
@@ -1,9 +1,13 @@
 package vcloud
 
 import (
+	"encoding/xml"
 	"fmt"
-	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
 )
 
 // Query ...
@@ -13,25 +17,201 @@ type Query struct {
 	Format    string
 	Filter    string
 	FilterArg string
-	Results   string
+	SortAsc   string
+	SortDesc  string
+	Fields    []string
+	PageSize  int
+	Page      int
+	// Deprecated: Results is only used as the format when Format is empty.
+	Results string
+}
+
+// QueryIterator steps through the pages of a query's results, following
+// the nextPage link returned with each page.
+type QueryIterator struct {
+	query *Query
+	next  string
+	page  *t.QueryResultRecords
+	err   error
+}
+
+// FilterEquals ...
+func FilterEquals(attribute, value string) string {
+	return attribute + "==" + escapeFilterValue(value)
+}
+
+// FilterNotEquals ...
+func FilterNotEquals(attribute, value string) string {
+	return attribute + "!=" + escapeFilterValue(value)
+}
+
+// FilterLessThan ...
+func FilterLessThan(attribute, value string) string {
+	return attribute + "=lt=" + escapeFilterValue(value)
+}
+
+// FilterLessOrEqual ...
+func FilterLessOrEqual(attribute, value string) string {
+	return attribute + "=le=" + escapeFilterValue(value)
+}
+
+// FilterGreaterThan ...
+func FilterGreaterThan(attribute, value string) string {
+	return attribute + "=gt=" + escapeFilterValue(value)
+}
+
+// FilterGreaterOrEqual ...
+func FilterGreaterOrEqual(attribute, value string) string {
+	return attribute + "=ge=" + escapeFilterValue(value)
+}
+
+// FilterAnd ...
+func FilterAnd(filters ...string) string {
+	return "(" + strings.Join(filters, ";") + ")"
+}
+
+// FilterOr ...
+func FilterOr(filters ...string) string {
+	return "(" + strings.Join(filters, ",") + ")"
+}
+
+// escapeFilterValue encodes the characters that would otherwise be read as
+// filter operators. Wildcards are left untouched.
+func escapeFilterValue(value string) string {
+	r := strings.NewReplacer(
+		"%", "%25",
+		";", "%3B",
+		",", "%2C",
+		"(", "%28",
+		")", "%29",
+	)
+	return r.Replace(value)
 }
 
 func (q *Query) buildQueryURL() string {
 	query := url.Values{}
-	query.Add("type", q.Type)
-	query.Add("format", q.Format)
-	query.Add("filter", fmt.Sprintf("%s==%s", q.Filter, q.FilterArg))
-	fullQuery := fmt.Sprintf("/api/query?%s", query.Encode())
-	href, _ := url.QueryUnescape(fullQuery)
-	return href
+	query.Set("type", q.Type)
+
+	format := q.Format
+	if format == "" {
+		format = q.Results
+	}
+	if format == "" {
+		format = "records"
+	}
+	query.Set("format", format)
+
+	if q.FilterArg != "" {
+		query.Set("filter", FilterEquals(q.Filter, q.FilterArg))
+	} else if q.Filter != "" {
+		query.Set("filter", q.Filter)
+	}
+
+	if q.SortAsc != "" {
+		query.Set("sortAsc", q.SortAsc)
+	}
+
+	if q.SortDesc != "" {
+		query.Set("sortDesc", q.SortDesc)
+	}
+
+	if len(q.Fields) > 0 {
+		query.Set("fields", strings.Join(q.Fields, ","))
+	}
+
+	if q.PageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(q.PageSize))
+	}
+
+	if q.Page > 0 {
+		query.Set("page", strconv.Itoa(q.Page))
+	}
+
+	return fmt.Sprintf("https://%s/api/query?%s", q.Connector.Config.URL, query.Encode())
+}
+
+// Run fetches every page of the query and returns the combined records.
+func (q *Query) Run() (*t.QueryResultRecords, error) {
+	results := t.QueryResultRecords{}
+
+	it := q.Iterator()
+	for it.Next() {
+		mergeQueryResults(&results, it.Page())
+	}
+
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+
+	return &results, nil
 }
 
-// Run ...
-func (q *Query) Run() (*http.Response, error) {
-	href := q.buildQueryURL()
+// Iterator ...
+func (q *Query) Iterator() *QueryIterator {
+	return &QueryIterator{
+		query: q,
+		next:  q.buildQueryURL(),
+	}
+}
+
+// Next fetches the next page of results. It returns false once there are
+// no more pages or an error has occurred.
+func (i *QueryIterator) Next() bool {
+	if i.err != nil || i.next == "" {
+		return false
+	}
+
+	page, err := i.query.fetch(i.next)
+	if err != nil {
+		i.err = err
+		return false
+	}
+
+	i.page = page
+	i.next = findLinkByRel(page.Links, "nextPage")
+
+	return true
+}
+
+// Page ...
+func (i *QueryIterator) Page() *t.QueryResultRecords {
+	return i.page
+}
+
+// Err ...
+func (i *QueryIterator) Err() error {
+	return i.err
+}
+
+func (q *Query) fetch(href string) (*t.QueryResultRecords, error) {
 	resp, err := q.Connector.Get(href)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	results := t.QueryResultRecords{}
+	err = xml.Unmarshal(*data, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+func mergeQueryResults(dst, src *t.QueryResultRecords) {
+	dst.Total = src.Total
+	dst.PageSize = src.PageSize
+	dst.Page = src.Page
+	dst.EdgeGatewayRecords = append(dst.EdgeGatewayRecords, src.EdgeGatewayRecords...)
+	dst.VAppRecords = append(dst.VAppRecords, src.VAppRecords...)
+	dst.AdminVAppRecords = append(dst.AdminVAppRecords, src.AdminVAppRecords...)
+	dst.VMRecords = append(dst.VMRecords, src.VMRecords...)
+	dst.AdminVMRecords = append(dst.AdminVMRecords, src.AdminVMRecords...)
+	dst.OrgVdcNetworkRecords = append(dst.OrgVdcNetworkRecords, src.OrgVdcNetworkRecords...)
+	dst.OrgVdcRecords = append(dst.OrgVdcRecords, src.OrgVdcRecords...)
 }
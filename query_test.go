@@ -0,0 +1,133 @@
+package vcloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func queryHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+
+	if r.URL.Query().Get("type") == "edgeGateway" {
+		fmt.Fprintf(w, `<QueryResultRecords xmlns="http://www.vmware.com/vcloud/v1.5" total="1" pageSize="25" page="1">
+    <EdgeGatewayRecord name="gateway" href="https://%s/api/admin/edgeGateway/5b8a2ee0-8f4c-4bd4-93e3-1b1e6d1c2a7f" vdc="https://%s/api/vdc/7a1e40a6-9bc3-4d1c-b0e1-1d3c7c9b5e21" gatewayStatus="READY" haStatus="DISABLED" isBusy="false" numberOfExtNetworks="1" numberOfOrgNetworks="1"/>
+</QueryResultRecords>`, r.Host, r.Host)
+		return
+	}
+
+	if r.URL.Query().Get("page") == "2" {
+		fmt.Fprint(w, `<QueryResultRecords xmlns="http://www.vmware.com/vcloud/v1.5" total="3" pageSize="2" page="2">
+    <VMRecord name="vm-3" href="https://vcloud.example.com/api/vApp/vm-3" numberOfCpus="4" memoryMB="4096"/>
+</QueryResultRecords>`)
+		return
+	}
+
+	fmt.Fprintf(w, `<QueryResultRecords xmlns="http://www.vmware.com/vcloud/v1.5" total="3" pageSize="2" page="1">
+    <Link rel="nextPage" href="https://%s/api/query?type=vm&amp;format=records&amp;pageSize=2&amp;page=2"/>
+    <VMRecord name="vm-1" href="https://vcloud.example.com/api/vApp/vm-1" numberOfCpus="1" memoryMB="1024"/>
+    <VMRecord name="vm-2" href="https://vcloud.example.com/api/vApp/vm-2" numberOfCpus="2" memoryMB="2048"/>
+</QueryResultRecords>`, r.Host)
+}
+
+func TestQueryURL(t *testing.T) {
+	Convey("Given a query", t, func() {
+		c := NewConnector(&Config{URL: "vcloud.example.com"})
+
+		Convey("When using a single filter argument", func() {
+			q := Query{Connector: c, Type: "edgeGateway", Filter: "vdc", FilterArg: "https://vcloud.example.com/api/vdc/1"}
+			u, err := url.Parse(q.buildQueryURL())
+			Convey("The url should be absolute", func() {
+				So(err, ShouldBeNil)
+				So(u.Host, ShouldEqual, "vcloud.example.com")
+				So(u.Path, ShouldEqual, "/api/query")
+			})
+			Convey("The filter should be preserved", func() {
+				So(u.Query().Get("filter"), ShouldEqual, "vdc==https://vcloud.example.com/api/vdc/1")
+				So(u.Query().Get("format"), ShouldEqual, "records")
+			})
+		})
+
+		Convey("When using compound filters, sorting and paging", func() {
+			q := Query{
+				Connector: c,
+				Type:      "vm",
+				Filter: FilterAnd(
+					FilterEquals("name", "web-*"),
+					FilterOr(FilterGreaterThan("numberOfCpus", "2"), FilterLessThan("memoryMB", "1024")),
+				),
+				SortDesc: "memoryMB",
+				Fields:   []string{"name", "memoryMB"},
+				PageSize: 50,
+			}
+			u, _ := url.Parse(q.buildQueryURL())
+			Convey("The filter should be combined", func() {
+				So(u.Query().Get("filter"), ShouldEqual, "(name==web-*;(numberOfCpus=gt=2,memoryMB=lt=1024))")
+			})
+			Convey("The sorting, fields and page size should be set", func() {
+				So(u.Query().Get("sortDesc"), ShouldEqual, "memoryMB")
+				So(u.Query().Get("fields"), ShouldEqual, "name,memoryMB")
+				So(u.Query().Get("pageSize"), ShouldEqual, "50")
+			})
+		})
+
+		Convey("When only the deprecated results field is set", func() {
+			q := Query{Connector: c, Type: "vm", Results: "references"}
+			u, _ := url.Parse(q.buildQueryURL())
+			So(u.Query().Get("format"), ShouldEqual, "references")
+		})
+
+		Convey("When a filter value contains operator characters", func() {
+			So(FilterEquals("name", "a;b,c"), ShouldEqual, "name==a%3Bb%2Cc")
+		})
+	})
+}
+
+func TestQueryRun(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a paginated query", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		q := Query{Connector: c, Type: "vm", PageSize: 2}
+
+		Convey("When running the query", func() {
+			results, err := q.Run()
+			Convey("All pages should be fetched", func() {
+				So(err, ShouldBeNil)
+				So(results.VMRecords, ShouldHaveLength, 3)
+				So(results.VMRecords[2].Name, ShouldEqual, "vm-3")
+				So(results.VMRecords[2].MemoryMB, ShouldEqual, 4096)
+			})
+		})
+
+		Convey("When iterating over the query", func() {
+			var pages int
+			it := q.Iterator()
+			for it.Next() {
+				pages++
+			}
+			Convey("Each page should be returned", func() {
+				So(it.Err(), ShouldBeNil)
+				So(pages, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -0,0 +1,139 @@
+package vcloud
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how a connector retries requests that fail with a
+// transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles on each
+	// subsequent retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries.
+	MaxDelay time.Duration
+	// Jitter is the fraction, between 0 and 1, of each delay that is
+	// randomised.
+	Jitter float64
+	// Retryable decides whether a failed attempt should be retried. err is
+	// either a transport error or an *Error built from the response.
+	// IsRetryable is used when nil.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy ...
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.5,
+		Retryable:   IsRetryable,
+	}
+}
+
+// IsRetryable reports whether err is a transient failure: a dropped or
+// refused connection, a timeout, a 429, 502, 503 or 504 response, or an
+// entity that is busy with another task. Transport errors are only retried
+// for requests that are safe to replay.
+func IsRetryable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		switch e.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return e.MinorErrorCode == ErrorCodeBusyEntity
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// replayable reports whether a request that failed with err can be sent
+// again. A transport error may occur after the server has acted on the
+// request, so it is only replayed for idempotent methods, or when the
+// connection was never established.
+func replayable(method string, err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return true
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	var oe *net.OpError
+	return errors.As(err, &oe) && oe.Op == "dial"
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// delay returns how long to wait before the given retry, preferring the
+// server's Retry-After header when one was sent.
+func (p *RetryPolicy) delay(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+
+	d := p.BaseDelay
+	for i := 1; i < retry && d < math.MaxInt64/2; i++ {
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+
+	return d
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
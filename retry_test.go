@@ -0,0 +1,73 @@
+package vcloud
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryDelay(t *testing.T) {
+	Convey("Given a retry policy", t, func() {
+		p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+		Convey("Delays should back off exponentially up to the cap", func() {
+			So(p.delay(1, nil), ShouldEqual, time.Second)
+			So(p.delay(2, nil), ShouldEqual, 2*time.Second)
+			So(p.delay(3, nil), ShouldEqual, 4*time.Second)
+			So(p.delay(4, nil), ShouldEqual, 5*time.Second)
+		})
+
+		Convey("Delays should keep growing when there is no cap", func() {
+			p.MaxDelay = 0
+			So(p.delay(1, nil), ShouldEqual, time.Second)
+			So(p.delay(2, nil), ShouldEqual, 2*time.Second)
+			So(p.delay(3, nil), ShouldEqual, 4*time.Second)
+			So(p.delay(6, nil), ShouldEqual, 32*time.Second)
+		})
+
+		Convey("Jitter should only shorten the delay", func() {
+			p.Jitter = 0.5
+			d := p.delay(2, nil)
+			So(d, ShouldBeLessThanOrEqualTo, 2*time.Second)
+			So(d, ShouldBeGreaterThanOrEqualTo, time.Second)
+		})
+
+		Convey("Retry-After should be honoured", func() {
+			resp := &http.Response{Header: http.Header{}}
+			resp.Header.Set("Retry-After", "7")
+			So(p.delay(1, resp), ShouldEqual, 7*time.Second)
+		})
+	})
+}
+
+func TestRetryReplay(t *testing.T) {
+	Convey("Given a failed request", t, func() {
+		dropped := &url.Error{Op: "Post", URL: "https://vcloud.example.com", Err: io.EOF}
+		refused := &url.Error{Op: "Post", URL: "https://vcloud.example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+		busy := &Error{StatusCode: 400, MinorErrorCode: ErrorCodeBusyEntity}
+
+		Convey("Idempotent requests should be replayed after a dropped connection", func() {
+			So(replayable(http.MethodGet, dropped), ShouldBeTrue)
+			So(replayable(http.MethodPut, dropped), ShouldBeTrue)
+			So(replayable(http.MethodDelete, dropped), ShouldBeTrue)
+		})
+
+		Convey("Posts should not be replayed after a dropped connection", func() {
+			So(replayable(http.MethodPost, dropped), ShouldBeFalse)
+		})
+
+		Convey("Posts should be replayed if they were never sent", func() {
+			So(replayable(http.MethodPost, refused), ShouldBeTrue)
+		})
+
+		Convey("Posts should be replayed after an error response", func() {
+			So(replayable(http.MethodPost, busy), ShouldBeTrue)
+		})
+	})
+}
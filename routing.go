@@ -0,0 +1,126 @@
+package vcloud
+
+import (
+	"fmt"
+	"net"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// Static route interface types
+const (
+	RouteInterfaceExternal = "External"
+	RouteInterfaceInternal = "Internal"
+)
+
+// StaticRoutes ...
+func (g *EdgeGateway) StaticRoutes() []t.StaticRoute {
+	routing := g.services().StaticRoutingService
+	if routing == nil {
+		return nil
+	}
+	return routing.StaticRoutes
+}
+
+// AddStaticRoute routes traffic for the given cidr to nextHop, through the
+// gateway's interface on the named network. The next hop must be reachable
+// from that interface's subnet.
+func (g *EdgeGateway) AddStaticRoute(name, cidr, nextHop, network string) (*Task, error) {
+	iface, err := g.gatewayInterface(network)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateStaticRoute(iface, cidr, nextHop)
+	if err != nil {
+		return nil, err
+	}
+
+	route := t.StaticRoute{
+		Name:      name,
+		Network:   cidr,
+		NextHopIP: nextHop,
+		Interface: RouteInterfaceInternal,
+		GatewayInterface: &t.Reference{
+			Href: iface.Network.Href,
+			Type: iface.Network.Type,
+			Name: iface.Network.Name,
+		},
+	}
+
+	if iface.InterfaceType == "uplink" {
+		route.Interface = RouteInterfaceExternal
+	}
+
+	return g.updateService(staticRoutingService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		routing := services.StaticRoutingService
+		for _, r := range routing.StaticRoutes {
+			if r.Name == name {
+				return fmt.Errorf("static route %s already exists on edge gateway %s", name, g.Name)
+			}
+		}
+		routing.StaticRoutes = append(routing.StaticRoutes, route)
+		return nil
+	})
+}
+
+// RemoveStaticRoute ...
+func (g *EdgeGateway) RemoveStaticRoute(name string) (*Task, error) {
+	return g.updateService(staticRoutingService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		routing := services.StaticRoutingService
+		for i, r := range routing.StaticRoutes {
+			if r.Name == name {
+				routing.StaticRoutes = append(routing.StaticRoutes[:i], routing.StaticRoutes[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("could not find static route %s on edge gateway %s", name, g.Name)
+	})
+}
+
+// SetStaticRoutingEnabled ...
+func (g *EdgeGateway) SetStaticRoutingEnabled(enabled bool) (*Task, error) {
+	return g.updateService(staticRoutingService, func(services *t.EdgeGatewayServiceConfiguration) error {
+		routing := services.StaticRoutingService
+		routing.IsEnabled = enabled
+		return nil
+	})
+}
+
+// staticRoutingService selects the static routing service for updateService.
+func staticRoutingService(dst, src *t.EdgeGatewayServiceConfiguration) {
+	routing := t.StaticRoutingService{IsEnabled: true}
+	if current := src.StaticRoutingService; current != nil {
+		routing = *current
+		routing.StaticRoutes = append([]t.StaticRoute(nil), current.StaticRoutes...)
+	}
+	dst.StaticRoutingService = &routing
+}
+
+func validateStaticRoute(iface *t.GatewayInterface, cidr, nextHop string) error {
+	_, _, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid static route network %s", cidr)
+	}
+
+	hop, err := parseIP(nextHop)
+	if err != nil {
+		return err
+	}
+
+	sp := iface.SubnetParticipation
+	subnet, err := parseSubnet(sp.Gateway, sp.Netmask)
+	if err != nil {
+		return fmt.Errorf("gateway interface %s: %s", iface.Name, err)
+	}
+
+	if !subnet.Contains(hop) {
+		return fmt.Errorf("next hop %s is not reachable from gateway interface %s (%s)", nextHop, iface.Name, subnet)
+	}
+
+	if sp.IPAddress == nextHop {
+		return fmt.Errorf("next hop %s is the gateway interface's own address", nextHop)
+	}
+
+	return nil
+}
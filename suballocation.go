@@ -0,0 +1,221 @@
+package vcloud
+
+import (
+	"fmt"
+	"net"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+// SubAllocations returns the external ip ranges sub-allocated to the gateway,
+// keyed by uplink network name.
+func (g *EdgeGateway) SubAllocations() map[string][]t.IPRange {
+	allocations := make(map[string][]t.IPRange)
+	for _, iface := range g.Configuration.GatewayInterfaces.Interfaces {
+		if iface.InterfaceType != "uplink" {
+			continue
+		}
+		allocations[iface.Network.Name] = subAllocatedRanges(&iface)
+	}
+	return allocations
+}
+
+// GetSubAllocations returns the ip ranges sub-allocated to the gateway on the
+// named uplink network.
+func (g *EdgeGateway) GetSubAllocations(network string) ([]t.IPRange, error) {
+	iface, err := g.uplinkInterface(network)
+	if err != nil {
+		return nil, err
+	}
+	return subAllocatedRanges(iface), nil
+}
+
+// AllocateIPRange sub-allocates an ip range from the named uplink network to
+// the gateway. The range must be inside the uplink's subnet and must not
+// overlap any range already sub-allocated to the gateway.
+func (g *EdgeGateway) AllocateIPRange(network, start, end string) (*Task, error) {
+	iface, err := g.uplinkInterface(network)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateSubAllocation(iface, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.update(func(config *t.GatewayConfiguration) error {
+		interfaces := config.GatewayInterfaces.Interfaces
+		sp := &interfaces[findGatewayInterface(interfaces, network)].SubnetParticipation
+		if sp.IPRanges == nil {
+			sp.IPRanges = &t.IPRanges{}
+		}
+		sp.IPRanges.IPRange = append(sp.IPRanges.IPRange, t.IPRange{StartAddress: start, EndAddress: end})
+		return nil
+	})
+}
+
+// ReleaseIPRange returns a sub-allocated ip range to the uplink network. Ranges
+// containing addresses still used by the gateway cannot be released.
+func (g *EdgeGateway) ReleaseIPRange(network, start, end string) (*Task, error) {
+	iface, err := g.uplinkInterface(network)
+	if err != nil {
+		return nil, err
+	}
+
+	s, e, err := parseIPRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for ip := range g.usedExternalIPs(iface) {
+		if ipInRange(net.ParseIP(ip), s, e) {
+			return nil, fmt.Errorf("cannot release ip range %s-%s, %s is still in use", start, end, ip)
+		}
+	}
+
+	return g.update(func(config *t.GatewayConfiguration) error {
+		interfaces := config.GatewayInterfaces.Interfaces
+		sp := &interfaces[findGatewayInterface(interfaces, network)].SubnetParticipation
+		if sp.IPRanges != nil {
+			for i, r := range sp.IPRanges.IPRange {
+				if r.StartAddress == start && r.EndAddress == end {
+					sp.IPRanges.IPRange = append(sp.IPRanges.IPRange[:i], sp.IPRanges.IPRange[i+1:]...)
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("ip range %s-%s is not sub-allocated to edge gateway %s", start, end, g.Name)
+	})
+}
+
+// NextFreeIP returns the first sub-allocated address on the named uplink
+// network that is not used by the gateway's interface, nat rules, vpn
+// endpoints or load balancer virtual servers.
+func (g *EdgeGateway) NextFreeIP(network string) (string, error) {
+	iface, err := g.uplinkInterface(network)
+	if err != nil {
+		return "", err
+	}
+
+	used := g.usedExternalIPs(iface)
+
+	for _, r := range subAllocatedRanges(iface) {
+		start, end, err := parseIPRange(r.StartAddress, r.EndAddress)
+		if err != nil {
+			return "", err
+		}
+
+		for ip := start; compareIPs(ip, end) <= 0; ip = nextIP(ip) {
+			if !used[ip.String()] {
+				return ip.String(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no free ip addresses sub-allocated to edge gateway %s on network %s", g.Name, network)
+}
+
+func (g *EdgeGateway) uplinkInterface(network string) (*t.GatewayInterface, error) {
+	iface, err := g.gatewayInterface(network)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface.InterfaceType != "uplink" {
+		return nil, fmt.Errorf("edge gateway %s interface on network %s is not an uplink", g.Name, network)
+	}
+
+	return iface, nil
+}
+
+// usedExternalIPs returns the addresses the gateway uses on an uplink.
+func (g *EdgeGateway) usedExternalIPs(iface *t.GatewayInterface) map[string]bool {
+	used := map[string]bool{}
+
+	add := func(ip string) {
+		if parsed := net.ParseIP(ip); parsed != nil {
+			used[parsed.String()] = true
+		}
+	}
+
+	add(iface.SubnetParticipation.IPAddress)
+
+	for _, rule := range g.NatRules() {
+		r := rule.GatewayNatRule
+		if r == nil || r.Interface == nil || r.Interface.Href != iface.Network.Href {
+			continue
+		}
+
+		switch rule.RuleType {
+		case NatRuleTypeDNAT:
+			add(r.OriginalIP)
+		case NatRuleTypeSNAT:
+			add(r.TranslatedIP)
+		}
+	}
+
+	for _, e := range g.IpsecVpnEndpoints() {
+		if e.Network.Href == iface.Network.Href {
+			add(e.PublicIP)
+		}
+	}
+
+	for _, vs := range g.LoadBalancerVirtualServers() {
+		if vs.Interface != nil && vs.Interface.Href == iface.Network.Href {
+			add(vs.IPAddress)
+		}
+	}
+
+	return used
+}
+
+func subAllocatedRanges(iface *t.GatewayInterface) []t.IPRange {
+	if iface.SubnetParticipation.IPRanges == nil {
+		return nil
+	}
+	return iface.SubnetParticipation.IPRanges.IPRange
+}
+
+func findGatewayInterface(interfaces []t.GatewayInterface, network string) int {
+	for i, iface := range interfaces {
+		if iface.Network.Name == network {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateSubAllocation(iface *t.GatewayInterface, start, end string) error {
+	s, e, err := parseIPRange(start, end)
+	if err != nil {
+		return err
+	}
+
+	sp := iface.SubnetParticipation
+	subnet, err := parseSubnet(sp.Gateway, sp.Netmask)
+	if err != nil {
+		return fmt.Errorf("gateway interface %s: %s", iface.Name, err)
+	}
+
+	if !subnet.Contains(s) || !subnet.Contains(e) {
+		return fmt.Errorf("ip range %s-%s is outside of subnet %s", start, end, subnet)
+	}
+
+	if gw := net.ParseIP(sp.Gateway); ipInRange(gw, s, e) {
+		return fmt.Errorf("ip range %s-%s includes the subnet gateway %s", start, end, sp.Gateway)
+	}
+
+	for _, r := range subAllocatedRanges(iface) {
+		rs, re, err := parseIPRange(r.StartAddress, r.EndAddress)
+		if err != nil {
+			return err
+		}
+
+		if ipRangesOverlap(s, e, rs, re) {
+			return fmt.Errorf("ip range %s-%s overlaps sub-allocated range %s-%s", start, end, r.StartAddress, r.EndAddress)
+		}
+	}
+
+	return nil
+}
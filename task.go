@@ -1,14 +1,26 @@
 package vcloud
 
 import (
+	"context"
 	"encoding/xml"
-	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	t "git.r3labs.io/libraries/go-vcloud/types"
 )
 
+// Task statuses
+const (
+	TaskStatusQueued     = "queued"
+	TaskStatusPreRunning = "preRunning"
+	TaskStatusRunning    = "running"
+	TaskStatusSuccess    = "success"
+	TaskStatusError      = "error"
+	TaskStatusCanceled   = "canceled"
+	TaskStatusAborted    = "aborted"
+)
+
 // Tasks ...
 type Tasks struct {
 	XMLName xml.Name `xml:"Tasks"`
@@ -22,15 +34,59 @@ type Task struct {
 	Name          string     `xml:"name,attr"`
 	Href          string     `xml:"href,attr"`
 	OperationName string     `xml:"operationName,attr"`
+	Operation     string     `xml:"operation,attr"`
 	Status        string     `xml:"status,attr"`
 	StartTime     string     `xml:"startTime,attr"`
+	EndTime       string     `xml:"endTime,attr"`
 	ExpiryTime    string     `xml:"expiryTime,attr"`
-	Cancel        bool       `xml:"cancelRequested"`
+	Cancel        bool       `xml:"cancelRequested,attr"`
 	Links         []t.Link   `xml:"Link"`
+	Description   string     `xml:"Description"`
 	Owner         t.Link     `xml:"Owner"`
-	User          t.Link     `xml:"User"`
 	Error         *t.Error   `xml:"Error"`
+	User          t.Link     `xml:"User"`
 	Organization  t.Link     `xml:"Organization"`
+	Progress      int        `xml:"Progress"`
+}
+
+// WaitOptions controls how a task is polled while waiting for it to
+// complete. Zero values are replaced with the defaults used by Wait.
+type WaitOptions struct {
+	// Timeout is the maximum time to wait. Zero waits indefinitely.
+	Timeout time.Duration
+	// Interval is the delay before the first poll.
+	Interval time.Duration
+	// MaxInterval caps the delay between polls.
+	MaxInterval time.Duration
+	// Multiplier is applied to the delay after each poll.
+	Multiplier float64
+	// Progress is called with the task's progress and status on each poll.
+	Progress func(progress int, status string)
+}
+
+// TaskError is returned when a task finishes with an error, or is aborted
+// or canceled.
+type TaskError struct {
+	Name   string
+	Href   string
+	Status string
+	Err    *t.Error
+}
+
+func (e *TaskError) Error() string {
+	if e.Err != nil && e.Err.Message != "" {
+		return fmt.Sprintf("task %s %s: %s", e.Name, e.Status, e.Err.Message)
+	}
+	return fmt.Sprintf("task %s %s", e.Name, e.Status)
+}
+
+// Unwrap returns the task's error as an *Error, so that it can be inspected
+// with errors.As or the Is* predicates.
+func (e *TaskError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return errorFromType(e.Err)
 }
 
 // NewTask ...
@@ -63,22 +119,110 @@ func ParseTask(d *[]byte) *Task {
 
 // Wait ...
 func (t *Task) Wait() error {
+	return t.WaitWithOptions(t.Connector.Context(), nil)
+}
+
+// WaitWithContext polls the task until it completes or ctx is done.
+func (t *Task) WaitWithContext(ctx context.Context) error {
+	return t.WaitWithOptions(ctx, nil)
+}
+
+// WaitWithOptions polls the task until it completes, ctx is done or the
+// configured timeout is reached. The task is updated in place on each poll.
+func (t *Task) WaitWithOptions(ctx context.Context, opts *WaitOptions) error {
+	o := defaultWaitOptions(opts)
+
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	c := t.Connector
+	t.Connector = c.WithContext(ctx)
+	defer func() { t.Connector = c }()
+
+	interval := o.Interval
+
 	for {
-		t, _ = NewTask(t.Connector, t.Href)
-		if t.Status == "success" {
+		err := t.Reload()
+		if err != nil {
+			return err
+		}
+
+		if o.Progress != nil {
+			o.Progress(t.Progress, t.Status)
+		}
+
+		switch t.Status {
+		case TaskStatusSuccess:
 			return nil
-		} else if t.Status == "error" {
-			return errors.New(t.Error.Message)
+		case TaskStatusError, TaskStatusCanceled, TaskStatusAborted:
+			return &TaskError{Name: t.Name, Href: t.Href, Status: t.Status, Err: t.Error}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval = time.Duration(float64(interval) * o.Multiplier)
+		if interval > o.MaxInterval {
+			interval = o.MaxInterval
 		}
-		time.Sleep(1 * time.Second)
 	}
 }
 
 // Reload ...
 func (t *Task) Reload() error {
 	updated, err := NewTask(t.Connector, t.Href)
-	if err == nil {
-		t = updated
+	if err != nil {
+		return err
+	}
+	*t = *updated
+	return nil
+}
+
+// RequestCancel asks vCloud to cancel the task. The task's Cancel field is
+// set once the request has been accepted and the task is next reloaded.
+func (t *Task) RequestCancel() error {
+	href := findLinkByRel(t.Links, "task:cancel")
+	if href == "" {
+		return fmt.Errorf("task %s cannot be cancelled", t.Name)
+	}
+
+	resp, err := t.Connector.Request(t.Connector.Context(), "POST", href, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+func defaultWaitOptions(opts *WaitOptions) WaitOptions {
+	o := WaitOptions{}
+	if opts != nil {
+		o = *opts
 	}
-	return err
+
+	if o.Interval <= 0 {
+		o.Interval = 1 * time.Second
+	}
+
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 10 * time.Second
+	}
+
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = o.Interval
+	}
+
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+
+	return o
 }
@@ -0,0 +1,179 @@
+package vcloud
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	types "git.r3labs.io/libraries/go-vcloud/types"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var taskPolls int
+
+func taskHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	taskPolls++
+
+	status := "running"
+	progress := taskPolls * 50
+	if taskPolls > 1 {
+		status = ps.ByName("id")
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<Task xmlns="http://www.vmware.com/vcloud/v1.5" name="task" status="%s" href="https://%s%s">`, status, r.Host, r.URL.Path)
+	if status == "error" {
+		fmt.Fprint(w, `<Error minorErrorCode="BAD_REQUEST" message="The requested operation could not be executed" majorErrorCode="400"/>`)
+	}
+	fmt.Fprintf(w, `<Progress>%d</Progress></Task>`, progress)
+}
+
+// taskCancelHandler only accepts cancellation of a running task, as vCloud
+// rejects cancelling a task that has already finished.
+func taskCancelHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	if ps.ByName("id") != "running" {
+		http.Error(w, `<Error xmlns="http://www.vmware.com/vcloud/v1.5" minorErrorCode="BAD_REQUEST" message="The task has already completed" majorErrorCode="400"/>`, 400)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func TestTaskWait(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a running task", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+		taskPolls = 0
+
+		opts := WaitOptions{Interval: time.Millisecond}
+
+		Convey("When the task succeeds", func() {
+			var updates []int
+			opts.Progress = func(progress int, status string) {
+				updates = append(updates, progress)
+			}
+			task := Task{Connector: c, Href: fmt.Sprintf("https://%s/api/task/success", tsurl.Host)}
+			err := task.WaitWithOptions(c.Context(), &opts)
+			Convey("There should be no error", func() {
+				So(err, ShouldBeNil)
+			})
+			Convey("The task should be updated in place", func() {
+				So(task.Status, ShouldEqual, TaskStatusSuccess)
+				So(task.Connector, ShouldEqual, c)
+			})
+			Convey("Progress should be reported on each poll", func() {
+				So(updates, ShouldResemble, []int{50, 100})
+			})
+		})
+
+		Convey("When the task fails", func() {
+			task := Task{Connector: c, Href: fmt.Sprintf("https://%s/api/task/error", tsurl.Host)}
+			err := task.WaitWithOptions(c.Context(), &opts)
+			Convey("A task error should be returned", func() {
+				terr, ok := err.(*TaskError)
+				So(ok, ShouldBeTrue)
+				So(terr.Status, ShouldEqual, TaskStatusError)
+				So(terr.Err.MinorErrorCode, ShouldEqual, "BAD_REQUEST")
+			})
+			Convey("The task error should unwrap to a vcloud error", func() {
+				var vErr *Error
+				So(errors.As(err, &vErr), ShouldBeTrue)
+				So(vErr.MinorErrorCode, ShouldEqual, ErrorCodeBadRequest)
+			})
+		})
+
+		Convey("When the task is aborted", func() {
+			task := Task{Connector: c, Href: fmt.Sprintf("https://%s/api/task/aborted", tsurl.Host)}
+			err := task.WaitWithOptions(c.Context(), &opts)
+			Convey("A task error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.(*TaskError).Status, ShouldEqual, TaskStatusAborted)
+			})
+		})
+
+		Convey("When the timeout is reached", func() {
+			opts.Interval = time.Second
+			opts.Timeout = 10 * time.Millisecond
+			task := Task{Connector: c, Href: fmt.Sprintf("https://%s/api/task/success", tsurl.Host)}
+			err := task.WaitWithOptions(c.Context(), &opts)
+			Convey("A deadline error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(task.Status, ShouldEqual, TaskStatusRunning)
+			})
+		})
+	})
+}
+
+func TestTaskCancel(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a task", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		Convey("When the task has a cancellation already requested", func() {
+			data := []byte(`<Task xmlns="http://www.vmware.com/vcloud/v1.5" name="task" status="running" cancelRequested="true"/>`)
+			task := ParseTask(&data)
+			Convey("The cancel flag should be set", func() {
+				So(task.Cancel, ShouldBeTrue)
+			})
+		})
+
+		Convey("When cancelling a running task", func() {
+			href := fmt.Sprintf("https://%s/api/task/running", tsurl.Host)
+			task := Task{Connector: c, Name: "task", Href: href, Links: []types.Link{
+				{Rel: "task:cancel", Href: href + "/action/cancel"},
+			}}
+			Convey("The cancellation should be accepted", func() {
+				So(task.RequestCancel(), ShouldBeNil)
+			})
+		})
+
+		Convey("When cancelling a finished task", func() {
+			href := fmt.Sprintf("https://%s/api/task/success", tsurl.Host)
+			task := Task{Connector: c, Name: "task", Href: href, Links: []types.Link{
+				{Rel: "task:cancel", Href: href + "/action/cancel"},
+			}}
+			Convey("The error should be returned", func() {
+				var vErr *Error
+				So(errors.As(task.RequestCancel(), &vErr), ShouldBeTrue)
+				So(vErr.MinorErrorCode, ShouldEqual, ErrorCodeBadRequest)
+			})
+		})
+
+		Convey("When the task cannot be cancelled", func() {
+			task := Task{Connector: c, Name: "task"}
+			So(task.RequestCancel(), ShouldNotBeNil)
+		})
+	})
+}
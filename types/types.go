@@ -10,6 +10,26 @@ type Link struct {
 	Href string `xml:"href,attr"`
 }
 
+// Reference ...
+type Reference struct {
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Name string `xml:"name,attr,omitempty"`
+}
+
+// SupportedVersions ...
+type SupportedVersions struct {
+	XMLName     xml.Name      `xml:"SupportedVersions"`
+	VersionInfo []VersionInfo `xml:"VersionInfo"`
+}
+
+// VersionInfo ...
+type VersionInfo struct {
+	Deprecated bool   `xml:"deprecated,attr"`
+	Version    string `xml:"Version"`
+	LoginURL   string `xml:"LoginUrl"`
+}
+
 // OrgList ...
 type OrgList struct {
 	XMLName xml.Name     `xml:"OrgList"`
@@ -18,10 +38,12 @@ type OrgList struct {
 
 // Error ...
 type Error struct {
-	XMLName        xml.Name `xml:"Error"`
-	MinorErrorCode string   `xml:"minorErrorCode,attr"`
-	MajorErrorCode string   `xml:"majorErrorCode,attr"`
-	Message        string   `xml:"message,attr"`
+	XMLName                 xml.Name `xml:"Error"`
+	MinorErrorCode          string   `xml:"minorErrorCode,attr"`
+	MajorErrorCode          string   `xml:"majorErrorCode,attr"`
+	VendorSpecificErrorCode string   `xml:"vendorSpecificErrorCode,attr,omitempty"`
+	Message                 string   `xml:"message,attr"`
+	StackTrace              string   `xml:"stackTrace,attr,omitempty"`
 }
 
 // OrgListOrg ...
@@ -70,19 +92,37 @@ type AllocatedIPAddresses struct {
 	IPAddress []string `xml:"IpAddress,value"`
 }
 
+// AllocatedIPAddressList ...
+type AllocatedIPAddressList struct {
+	XMLName     xml.Name             `xml:"AllocatedIpAddresses"`
+	Href        string               `xml:"href,attr"`
+	Type        string               `xml:"type,attr"`
+	Links       []Link               `xml:"Link"`
+	IPAddresses []AllocatedIPAddress `xml:"IpAddress"`
+}
+
+// AllocatedIPAddress ...
+type AllocatedIPAddress struct {
+	AllocationType string `xml:"allocationType,attr"`
+	IsDeployed     bool   `xml:"isDeployed,attr"`
+	Links          []Link `xml:"Link"`
+	IPAddress      string `xml:"IpAddress"`
+}
+
 // IPScope ...
 type IPScope struct {
 	XMLName              xml.Name              `xml:"IpScope"`
 	IsInherited          bool                  `xml:"IsInherited,value"`
 	Gateway              string                `xml:"Gateway,value"`
-	Netmask              string                `xml:"Netmask,value"`
+	Netmask              string                `xml:"Netmask,value,omitempty"`
+	SubnetPrefixLength   int                   `xml:"SubnetPrefixLength,omitempty"`
 	DNS1                 string                `xml:"Dns1,value,omitempty"`
 	DNS2                 string                `xml:"Dns2,value,omitempty"`
 	DNSSuffix            string                `xml:"DnsSuffix,value,omitempty"`
 	IsEnabled            bool                  `xml:"IsEnabled,value,omitempty"`
 	IPRanges             IPRanges              `xml:"IpRanges"`
-	SubAllocations       *SubAllocations       `xml:"SubAllocations"`
 	AllocatedIPAddresses *AllocatedIPAddresses `xml:"AllocatedIpAddresses"`
+	SubAllocations       *SubAllocations       `xml:"SubAllocations"`
 }
 
 // IPScopes ...
@@ -91,16 +131,58 @@ type IPScopes struct {
 	IPScope []IPScope `xml:"IpScope"`
 }
 
-// Configuration ...
-type Configuration struct {
-	XMLName  xml.Name `xml:"Configuration"`
-	IPScopes IPScopes `xml:"IpScopes"`
+// MarshalXML omits the element when there are no scopes, as networks that
+// inherit their ip configuration, such as bridged networks, must not send
+// an empty IpScopes element.
+func (s IPScopes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(s.IPScope) < 1 {
+		return nil
+	}
+	type ipScopes IPScopes
+	return e.EncodeElement(ipScopes(s), start)
 }
 
-// ExternalNetwork ...
-type ExternalNetwork struct {
-	XMLName       xml.Name      `xml:"ExternalNetwork"`
-	Configuration Configuration `xml:"Configuration"`
+// NetworkServiceConfig ...
+type NetworkServiceConfig struct {
+	DhcpService *DhcpService `xml:"DhcpService,omitempty"`
+}
+
+// DhcpService ...
+type DhcpService struct {
+	IsEnabled        bool     `xml:"IsEnabled"`
+	DefaultLeaseTime int      `xml:"DefaultLeaseTime,omitempty"`
+	MaxLeaseTime     int      `xml:"MaxLeaseTime,omitempty"`
+	IPRange          *IPRange `xml:"IpRange,omitempty"`
+}
+
+// VimObjectRef ...
+type VimObjectRef struct {
+	VimServerRef  Reference `xml:"http://www.vmware.com/vcloud/extension/v1.5 VimServerRef"`
+	MoRef         string    `xml:"http://www.vmware.com/vcloud/extension/v1.5 MoRef"`
+	VimObjectType string    `xml:"http://www.vmware.com/vcloud/extension/v1.5 VimObjectType"`
+}
+
+// VimObjectRefs ...
+type VimObjectRefs struct {
+	VimObjectRef []VimObjectRef `xml:"http://www.vmware.com/vcloud/extension/v1.5 VimObjectRef"`
+}
+
+// VlanRange ...
+type VlanRange struct {
+	Start int `xml:"http://www.vmware.com/vcloud/extension/v1.5 Start"`
+	End   int `xml:"http://www.vmware.com/vcloud/extension/v1.5 End"`
+}
+
+// VMWExternalNetworkReferences ...
+type VMWExternalNetworkReferences struct {
+	XMLName    xml.Name    `xml:"http://www.vmware.com/vcloud/extension/v1.5 VMWExternalNetworkReferences"`
+	References []Reference `xml:"http://www.vmware.com/vcloud/extension/v1.5 ExternalNetworkReference"`
+}
+
+// VMWNetworkPoolReferences ...
+type VMWNetworkPoolReferences struct {
+	XMLName    xml.Name    `xml:"http://www.vmware.com/vcloud/extension/v1.5 VMWNetworkPoolReferences"`
+	References []Reference `xml:"http://www.vmware.com/vcloud/extension/v1.5 NetworkPoolReference"`
 }
 
 // ComputeCapacity ...
@@ -138,11 +220,108 @@ type ResourceEntities struct {
 
 // QueryResultRecords ...
 type QueryResultRecords struct {
-	XMLName            xml.Name `xml:"QueryResultRecords"`
-	Total              int      `xml:"total,attr"`
-	PageSize           int      `xml:"pageSize,attr"`
-	Page               int      `xml:"page,attr"`
-	EdgeGatewayRecords []Link   `xml:"EdgeGatewayRecord"`
+	XMLName              xml.Name              `xml:"QueryResultRecords"`
+	Total                int                   `xml:"total,attr"`
+	PageSize             int                   `xml:"pageSize,attr"`
+	Page                 int                   `xml:"page,attr"`
+	Links                []Link                `xml:"Link"`
+	EdgeGatewayRecords   []EdgeGatewayRecord   `xml:"EdgeGatewayRecord"`
+	VAppRecords          []VAppRecord          `xml:"VAppRecord"`
+	AdminVAppRecords     []AdminVAppRecord     `xml:"AdminVAppRecord"`
+	VMRecords            []VMRecord            `xml:"VMRecord"`
+	AdminVMRecords       []VMRecord            `xml:"AdminVMRecord"`
+	OrgVdcNetworkRecords []OrgVdcNetworkRecord `xml:"OrgVdcNetworkRecord"`
+	OrgVdcRecords        []OrgVdcRecord        `xml:"OrgVdcRecord"`
+}
+
+// EdgeGatewayRecord ...
+type EdgeGatewayRecord struct {
+	Name                string `xml:"name,attr"`
+	Href                string `xml:"href,attr"`
+	Vdc                 string `xml:"vdc,attr"`
+	GatewayStatus       string `xml:"gatewayStatus,attr"`
+	HaStatus            string `xml:"haStatus,attr"`
+	IsBusy              bool   `xml:"isBusy,attr"`
+	NumberOfExtNetworks int    `xml:"numberOfExtNetworks,attr"`
+	NumberOfOrgNetworks int    `xml:"numberOfOrgNetworks,attr"`
+	TaskStatus          string `xml:"taskStatus,attr"`
+}
+
+// VAppRecord ...
+type VAppRecord struct {
+	Name               string `xml:"name,attr"`
+	Href               string `xml:"href,attr"`
+	Vdc                string `xml:"vdc,attr"`
+	VdcName            string `xml:"vdcName,attr"`
+	OwnerName          string `xml:"ownerName,attr"`
+	Status             string `xml:"status,attr"`
+	IsDeployed         bool   `xml:"isDeployed,attr"`
+	IsEnabled          bool   `xml:"isEnabled,attr"`
+	IsExpired          bool   `xml:"isExpired,attr"`
+	IsBusy             bool   `xml:"isBusy,attr"`
+	NumberOfVMs        int    `xml:"numberOfVMs,attr"`
+	CPUAllocationMhz   int    `xml:"cpuAllocationMhz,attr"`
+	MemoryAllocationMB int    `xml:"memoryAllocationMB,attr"`
+	StorageKB          int    `xml:"storageKB,attr"`
+	CreationDate       string `xml:"creationDate,attr"`
+}
+
+// AdminVAppRecord ...
+type AdminVAppRecord struct {
+	VAppRecord
+	Org string `xml:"org,attr"`
+}
+
+// VMRecord ...
+type VMRecord struct {
+	Name            string `xml:"name,attr"`
+	Href            string `xml:"href,attr"`
+	Container       string `xml:"container,attr"`
+	ContainerName   string `xml:"containerName,attr"`
+	Vdc             string `xml:"vdc,attr"`
+	Org             string `xml:"org,attr"`
+	Status          string `xml:"status,attr"`
+	IsDeployed      bool   `xml:"isDeployed,attr"`
+	IsVAppTemplate  bool   `xml:"isVAppTemplate,attr"`
+	IsBusy          bool   `xml:"isBusy,attr"`
+	GuestOS         string `xml:"guestOs,attr"`
+	NumberOfCpus    int    `xml:"numberOfCpus,attr"`
+	MemoryMB        int    `xml:"memoryMB,attr"`
+	IPAddress       string `xml:"ipAddress,attr"`
+	NetworkName     string `xml:"networkName,attr"`
+	HardwareVersion int    `xml:"hardwareVersion,attr"`
+}
+
+// OrgVdcNetworkRecord ...
+type OrgVdcNetworkRecord struct {
+	Name           string `xml:"name,attr"`
+	Href           string `xml:"href,attr"`
+	Vdc            string `xml:"vdc,attr"`
+	VdcName        string `xml:"vdcName,attr"`
+	LinkType       int    `xml:"linkType,attr"`
+	ConnectedTo    string `xml:"connectedTo,attr"`
+	DefaultGateway string `xml:"defaultGateway,attr"`
+	Netmask        string `xml:"netmask,attr"`
+	DNS1           string `xml:"dns1,attr"`
+	DNS2           string `xml:"dns2,attr"`
+	DNSSuffix      string `xml:"dnsSuffix,attr"`
+	IsBusy         bool   `xml:"isBusy,attr"`
+	IsShared       bool   `xml:"isShared,attr"`
+}
+
+// OrgVdcRecord ...
+type OrgVdcRecord struct {
+	Name               string `xml:"name,attr"`
+	Href               string `xml:"href,attr"`
+	OrgName            string `xml:"orgName,attr"`
+	Status             string `xml:"status,attr"`
+	IsEnabled          bool   `xml:"isEnabled,attr"`
+	IsBusy             bool   `xml:"isBusy,attr"`
+	NumberOfVApps      int    `xml:"numberOfVApps,attr"`
+	CPUAllocationMhz   int    `xml:"cpuAllocationMhz,attr"`
+	CPUUsedMhz         int    `xml:"cpuUsedMhz,attr"`
+	MemoryAllocationMB int    `xml:"memoryAllocationMB,attr"`
+	MemoryUsedMB       int    `xml:"memoryUsedMB,attr"`
 }
 
 // GatewayConfiguration ...
@@ -150,16 +329,40 @@ type GatewayConfiguration struct {
 	XMLName                     xml.Name `xml:"Configuration"`
 	GatewayBackingConfiguration string   `xml:"GatewayBackingConfiguration,value"`
 	GatewayInterfaces           struct {
-		Interfaces []struct {
-			Name                string `xml:"Name,value"`
-			Network             Link   `xml:"Network"`
-			SubnetParticipation struct {
-				Gateway   string `xml:"Gateway,value"`
-				Netmask   string `xml:"Netmask,value"`
-				IPAddress string `xml:"IpAddress,value"`
-			} `xml:"SubnetParticipation"`
-		} `xml:"GatewayInterface"`
+		Interfaces []GatewayInterface `xml:"GatewayInterface"`
 	} `xml:"GatewayInterfaces"`
+	EdgeGatewayServiceConfiguration *EdgeGatewayServiceConfiguration `xml:"EdgeGatewayServiceConfiguration"`
+	HaEnabled                       bool                             `xml:"HaEnabled"`
+	UseDefaultRouteForDNSRelay      bool                             `xml:"UseDefaultRouteForDnsRelay"`
+	AdvancedNetworkingEnabled       *bool                            `xml:"AdvancedNetworkingEnabled,omitempty"`
+	DistributedRoutingEnabled       *bool                            `xml:"DistributedRoutingEnabled,omitempty"`
+}
+
+// EdgeGatewayFormFactor ...
+type EdgeGatewayFormFactor struct {
+	XMLName     xml.Name `xml:"http://www.vmware.com/vcloud/v1.5 EdgeGatewayFormFactor"`
+	GatewayType string   `xml:"gatewayType"`
+}
+
+// GatewayInterface ...
+type GatewayInterface struct {
+	Name                string              `xml:"Name,value"`
+	DisplayName         string              `xml:"DisplayName,omitempty"`
+	Network             Reference           `xml:"Network"`
+	InterfaceType       string              `xml:"InterfaceType,omitempty"`
+	SubnetParticipation SubnetParticipation `xml:"SubnetParticipation"`
+	ApplyRateLimit      bool                `xml:"ApplyRateLimit"`
+	InRateLimit         float64             `xml:"InRateLimit,omitempty"`
+	OutRateLimit        float64             `xml:"OutRateLimit,omitempty"`
+	UseForDefaultRoute  bool                `xml:"UseForDefaultRoute"`
+}
+
+// SubnetParticipation ...
+type SubnetParticipation struct {
+	Gateway   string    `xml:"Gateway,value"`
+	Netmask   string    `xml:"Netmask,value"`
+	IPAddress string    `xml:"IpAddress,value"`
+	IPRanges  *IPRanges `xml:"IpRanges,omitempty"`
 }
 
 // NetworkGateway ...
@@ -172,21 +375,319 @@ type NetworkGateway struct {
 
 // InstantiateVApp ...
 type InstantiateVApp struct {
-	XMLName xml.Name `xml:"http://www.vmware.com/vcloud/v1.5 InstantiateVAppTemplateParams"`
-	Params  struct {
-		NetworkConfig struct {
+	XMLName     xml.Name `xml:"http://www.vmware.com/vcloud/v1.5 InstantiateVAppTemplateParams"`
+	Name        string   `xml:"name,attr"`
+	Deploy      bool     `xml:"deploy,attr"`
+	PowerOn     bool     `xml:"powerOn,attr"`
+	Description string   `xml:"Description,omitempty"`
+	Params      struct {
+		NetworkConfigInfo string `xml:"http://schemas.dmtf.org/ovf/envelope/1 NetworkConfigSection>Info"`
+		NetworkConfig     struct {
 			NetworkName   string `xml:"networkName,attr"`
 			ParentNetwork struct {
 				Type string `xml:"type,attr"`
 				Name string `xml:"name,attr"`
 				Href string `xml:"href,attr"`
-			} `xml:"Configuration> ParentNetwork"`
-			FenceMode string `xml:"Configuration> FenceMode,value"`
-		} `xml:"NetworkConfigSection> NetworkConfig"`
-		Source struct {
-			Type string `xml:"type,attr"`
-			Name string `xml:"name,attr"`
-			Href string `xml:"href,attr"`
-		} `xml:"Source"`
+			} `xml:"Configuration>ParentNetwork"`
+			FenceMode string `xml:"Configuration>FenceMode"`
+		} `xml:"NetworkConfigSection>NetworkConfig"`
 	} `xml:"InstantiationParams"`
+	Source struct {
+		Type string `xml:"type,attr,omitempty"`
+		Name string `xml:"name,attr,omitempty"`
+		Href string `xml:"href,attr"`
+	} `xml:"Source"`
+	AllEULAsAccepted bool `xml:"AllEULAsAccepted,omitempty"`
+}
+
+// DeployVAppParams ...
+type DeployVAppParams struct {
+	XMLName                xml.Name `xml:"http://www.vmware.com/vcloud/v1.5 DeployVAppParams"`
+	PowerOn                bool     `xml:"powerOn,attr"`
+	DeploymentLeaseSeconds int      `xml:"deploymentLeaseSeconds,attr,omitempty"`
+	ForceCustomization     bool     `xml:"forceCustomization,attr,omitempty"`
+}
+
+// UndeployVAppParams ...
+type UndeployVAppParams struct {
+	XMLName             xml.Name `xml:"http://www.vmware.com/vcloud/v1.5 UndeployVAppParams"`
+	UndeployPowerAction string   `xml:"UndeployPowerAction,omitempty"`
+}
+
+// VirtualHardwareSection ...
+type VirtualHardwareSection struct {
+	XMLName xml.Name   `xml:"http://schemas.dmtf.org/ovf/envelope/1 VirtualHardwareSection"`
+	Href    string     `xml:"http://www.vmware.com/vcloud/v1.5 href,attr,omitempty"`
+	Type    string     `xml:"http://www.vmware.com/vcloud/v1.5 type,attr,omitempty"`
+	Info    string     `xml:"http://schemas.dmtf.org/ovf/envelope/1 Info"`
+	Items   []RasdItem `xml:"http://schemas.dmtf.org/ovf/envelope/1 Item"`
+	Links   []Link     `xml:"http://www.vmware.com/vcloud/v1.5 Link"`
+}
+
+// RasdItem ...
+type RasdItem struct {
+	XMLName              xml.Name
+	Href                 string          `xml:"http://www.vmware.com/vcloud/v1.5 href,attr,omitempty"`
+	Type                 string          `xml:"http://www.vmware.com/vcloud/v1.5 type,attr,omitempty"`
+	Address              string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData Address,omitempty"`
+	AddressOnParent      string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData AddressOnParent,omitempty"`
+	AllocationUnits      string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData AllocationUnits,omitempty"`
+	AutomaticAllocation  string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData AutomaticAllocation,omitempty"`
+	Description          string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData Description,omitempty"`
+	ElementName          string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData ElementName"`
+	HostResource         []HostResource  `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData HostResource"`
+	InstanceID           int             `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData InstanceID"`
+	Limit                string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData Limit,omitempty"`
+	Parent               string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData Parent,omitempty"`
+	Reservation          string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData Reservation,omitempty"`
+	ResourceSubType      string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData ResourceSubType,omitempty"`
+	ResourceType         int             `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData ResourceType"`
+	VirtualQuantity      int64           `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData VirtualQuantity,omitempty"`
+	VirtualQuantityUnits string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData VirtualQuantityUnits,omitempty"`
+	Weight               string          `xml:"http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData Weight,omitempty"`
+	CoresPerSocket       *CoresPerSocket `xml:"http://www.vmware.com/schema/ovf CoresPerSocket,omitempty"`
+	Links                []Link          `xml:"http://www.vmware.com/vcloud/v1.5 Link"`
+}
+
+// HostResource ...
+type HostResource struct {
+	Capacity           int    `xml:"http://www.vmware.com/vcloud/v1.5 capacity,attr,omitempty"`
+	BusType            string `xml:"http://www.vmware.com/vcloud/v1.5 busType,attr,omitempty"`
+	BusSubType         string `xml:"http://www.vmware.com/vcloud/v1.5 busSubType,attr,omitempty"`
+	StorageProfileHref string `xml:"http://www.vmware.com/vcloud/v1.5 storageProfileHref,attr,omitempty"`
+	Value              string `xml:",chardata"`
+}
+
+// CoresPerSocket ...
+type CoresPerSocket struct {
+	Required string `xml:"http://schemas.dmtf.org/ovf/envelope/1 required,attr,omitempty"`
+	Value    int    `xml:",chardata"`
+}
+
+// RasdItemsList ...
+type RasdItemsList struct {
+	XMLName xml.Name   `xml:"http://www.vmware.com/vcloud/v1.5 RasdItemsList"`
+	Href    string     `xml:"href,attr,omitempty"`
+	Type    string     `xml:"type,attr,omitempty"`
+	Items   []RasdItem `xml:"Item"`
+}
+
+// EdgeGatewayServiceConfiguration ...
+type EdgeGatewayServiceConfiguration struct {
+	XMLName                xml.Name                `xml:"http://www.vmware.com/vcloud/v1.5 EdgeGatewayServiceConfiguration"`
+	GatewayDhcpService     *GatewayDhcpService     `xml:"GatewayDhcpService,omitempty"`
+	FirewallService        *FirewallService        `xml:"FirewallService,omitempty"`
+	NatService             *NatService             `xml:"NatService,omitempty"`
+	GatewayIpsecVpnService *GatewayIpsecVpnService `xml:"GatewayIpsecVpnService,omitempty"`
+	StaticRoutingService   *StaticRoutingService   `xml:"StaticRoutingService,omitempty"`
+	LoadBalancerService    *LoadBalancerService    `xml:"LoadBalancerService,omitempty"`
+}
+
+// FirewallService ...
+type FirewallService struct {
+	IsEnabled        bool           `xml:"IsEnabled"`
+	DefaultAction    string         `xml:"DefaultAction,omitempty"`
+	LogDefaultAction bool           `xml:"LogDefaultAction"`
+	FirewallRules    []FirewallRule `xml:"FirewallRule"`
+}
+
+// FirewallRule ...
+type FirewallRule struct {
+	ID                   string                 `xml:"Id,omitempty"`
+	IsEnabled            bool                   `xml:"IsEnabled"`
+	MatchOnTranslate     bool                   `xml:"MatchOnTranslate"`
+	Description          string                 `xml:"Description,omitempty"`
+	Policy               string                 `xml:"Policy,omitempty"`
+	Protocols            *FirewallRuleProtocols `xml:"Protocols,omitempty"`
+	IcmpSubType          string                 `xml:"IcmpSubType,omitempty"`
+	Port                 int                    `xml:"Port,omitempty"`
+	DestinationPortRange string                 `xml:"DestinationPortRange"`
+	DestinationIP        string                 `xml:"DestinationIp"`
+	SourcePort           int                    `xml:"SourcePort,omitempty"`
+	SourcePortRange      string                 `xml:"SourcePortRange"`
+	SourceIP             string                 `xml:"SourceIp"`
+	Direction            string                 `xml:"Direction,omitempty"`
+	EnableLogging        bool                   `xml:"EnableLogging"`
+}
+
+// FirewallRuleProtocols ...
+type FirewallRuleProtocols struct {
+	ICMP bool `xml:"Icmp,omitempty"`
+	TCP  bool `xml:"Tcp,omitempty"`
+	UDP  bool `xml:"Udp,omitempty"`
+	Any  bool `xml:"Any,omitempty"`
+}
+
+// NatService ...
+type NatService struct {
+	IsEnabled bool      `xml:"IsEnabled"`
+	NatType   string    `xml:"NatType,omitempty"`
+	Policy    string    `xml:"Policy,omitempty"`
+	NatRules  []NatRule `xml:"NatRule"`
+}
+
+// NatRule ...
+type NatRule struct {
+	Description    string          `xml:"Description,omitempty"`
+	RuleType       string          `xml:"RuleType,omitempty"`
+	IsEnabled      bool            `xml:"IsEnabled"`
+	ID             string          `xml:"Id,omitempty"`
+	GatewayNatRule *GatewayNatRule `xml:"GatewayNatRule,omitempty"`
+}
+
+// GatewayNatRule ...
+type GatewayNatRule struct {
+	Interface      *Reference `xml:"Interface,omitempty"`
+	OriginalIP     string     `xml:"OriginalIp"`
+	OriginalPort   string     `xml:"OriginalPort,omitempty"`
+	TranslatedIP   string     `xml:"TranslatedIp"`
+	TranslatedPort string     `xml:"TranslatedPort,omitempty"`
+	Protocol       string     `xml:"Protocol,omitempty"`
+	IcmpSubType    string     `xml:"IcmpSubType,omitempty"`
+}
+
+// GatewayDhcpService ...
+type GatewayDhcpService struct {
+	IsEnabled bool       `xml:"IsEnabled"`
+	Pools     []DhcpPool `xml:"Pool"`
+}
+
+// DhcpPool ...
+type DhcpPool struct {
+	IsEnabled        bool      `xml:"IsEnabled"`
+	Network          Reference `xml:"Network"`
+	DefaultLeaseTime int       `xml:"DefaultLeaseTime,omitempty"`
+	MaxLeaseTime     int       `xml:"MaxLeaseTime"`
+	LowIPAddress     string    `xml:"LowIpAddress"`
+	HighIPAddress    string    `xml:"HighIpAddress"`
+}
+
+// StaticRoutingService ...
+type StaticRoutingService struct {
+	IsEnabled    bool          `xml:"IsEnabled"`
+	StaticRoutes []StaticRoute `xml:"StaticRoute"`
+}
+
+// StaticRoute ...
+type StaticRoute struct {
+	Name             string     `xml:"Name"`
+	Network          string     `xml:"Network"`
+	NextHopIP        string     `xml:"NextHopIp"`
+	Interface        string     `xml:"Interface,omitempty"`
+	GatewayInterface *Reference `xml:"GatewayInterface,omitempty"`
+}
+
+// GatewayIpsecVpnService ...
+type GatewayIpsecVpnService struct {
+	IsEnabled bool                      `xml:"IsEnabled"`
+	Endpoints []GatewayIpsecVpnEndpoint `xml:"Endpoint"`
+	Tunnels   []GatewayIpsecVpnTunnel   `xml:"Tunnel"`
+}
+
+// GatewayIpsecVpnEndpoint ...
+type GatewayIpsecVpnEndpoint struct {
+	Network  Reference `xml:"Network"`
+	PublicIP string    `xml:"PublicIp,omitempty"`
+}
+
+// GatewayIpsecVpnTunnel ...
+type GatewayIpsecVpnTunnel struct {
+	Name                   string                  `xml:"Name"`
+	Description            string                  `xml:"Description,omitempty"`
+	IpsecVpnThirdPartyPeer *IpsecVpnThirdPartyPeer `xml:"IpsecVpnThirdPartyPeer,omitempty"`
+	PeerIPAddress          string                  `xml:"PeerIpAddress"`
+	PeerID                 string                  `xml:"PeerId"`
+	LocalIPAddress         string                  `xml:"LocalIpAddress"`
+	LocalID                string                  `xml:"LocalId"`
+	LocalSubnets           []IpsecVpnSubnet        `xml:"LocalSubnet"`
+	PeerSubnets            []IpsecVpnSubnet        `xml:"PeerSubnet"`
+	SharedSecret           string                  `xml:"SharedSecret,omitempty"`
+	SharedSecretEncrypted  bool                    `xml:"SharedSecretEncrypted,omitempty"`
+	EncryptionProtocol     string                  `xml:"EncryptionProtocol"`
+	Mtu                    int                     `xml:"Mtu"`
+	IsEnabled              bool                    `xml:"IsEnabled"`
+	IsOperational          bool                    `xml:"IsOperational,omitempty"`
+	ErrorDetails           string                  `xml:"ErrorDetails,omitempty"`
+}
+
+// IpsecVpnThirdPartyPeer marks a tunnel whose peer is not managed by vCloud.
+type IpsecVpnThirdPartyPeer struct{}
+
+// IpsecVpnSubnet ...
+type IpsecVpnSubnet struct {
+	Name    string `xml:"Name"`
+	Gateway string `xml:"Gateway"`
+	Netmask string `xml:"Netmask"`
+}
+
+// LoadBalancerService ...
+type LoadBalancerService struct {
+	IsEnabled      bool                        `xml:"IsEnabled"`
+	Pools          []LoadBalancerPool          `xml:"Pool"`
+	VirtualServers []LoadBalancerVirtualServer `xml:"VirtualServer"`
+}
+
+// LoadBalancerPool ...
+type LoadBalancerPool struct {
+	ID           string                   `xml:"Id,omitempty"`
+	Name         string                   `xml:"Name"`
+	Description  string                   `xml:"Description,omitempty"`
+	ServicePorts []LoadBalancerPoolPort   `xml:"ServicePort"`
+	Members      []LoadBalancerPoolMember `xml:"Member"`
+	Operational  bool                     `xml:"Operational,omitempty"`
+	ErrorDetails string                   `xml:"ErrorDetails,omitempty"`
+}
+
+// LoadBalancerPoolPort ...
+type LoadBalancerPoolPort struct {
+	IsEnabled       bool                          `xml:"IsEnabled"`
+	Protocol        string                        `xml:"Protocol"`
+	Algorithm       string                        `xml:"Algorithm"`
+	Port            string                        `xml:"Port"`
+	HealthCheckPort string                        `xml:"HealthCheckPort"`
+	HealthChecks    []LoadBalancerPoolHealthCheck `xml:"HealthCheck"`
+}
+
+// LoadBalancerPoolHealthCheck ...
+type LoadBalancerPoolHealthCheck struct {
+	Mode              string `xml:"Mode"`
+	URI               string `xml:"Uri,omitempty"`
+	HealthThreshold   string `xml:"HealthThreshold"`
+	UnhealthThreshold string `xml:"UnhealthThreshold"`
+	Interval          string `xml:"Interval"`
+	Timeout           string `xml:"Timeout"`
+}
+
+// LoadBalancerPoolMember ...
+type LoadBalancerPoolMember struct {
+	IPAddress    string                 `xml:"IpAddress"`
+	Weight       string                 `xml:"Weight"`
+	ServicePorts []LoadBalancerPoolPort `xml:"ServicePort"`
+}
+
+// LoadBalancerVirtualServer ...
+type LoadBalancerVirtualServer struct {
+	IsEnabled       bool                         `xml:"IsEnabled"`
+	Name            string                       `xml:"Name"`
+	Description     string                       `xml:"Description,omitempty"`
+	Interface       *Reference                   `xml:"Interface"`
+	IPAddress       string                       `xml:"IpAddress"`
+	ServiceProfiles []LoadBalancerServiceProfile `xml:"ServiceProfile"`
+	Logging         bool                         `xml:"Logging"`
+	Pool            string                       `xml:"Pool"`
+}
+
+// LoadBalancerServiceProfile ...
+type LoadBalancerServiceProfile struct {
+	IsEnabled   bool                     `xml:"IsEnabled"`
+	Protocol    string                   `xml:"Protocol"`
+	Port        string                   `xml:"Port"`
+	Persistence *LoadBalancerPersistence `xml:"Persistence,omitempty"`
+}
+
+// LoadBalancerPersistence ...
+type LoadBalancerPersistence struct {
+	Method     string `xml:"Method"`
+	CookieName string `xml:"CookieName,omitempty"`
+	CookieMode string `xml:"CookieMode,omitempty"`
 }
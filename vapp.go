@@ -2,11 +2,26 @@ package vcloud
 
 import (
 	"encoding/xml"
+	"fmt"
 	"log"
 
 	t "git.r3labs.io/libraries/go-vcloud/types"
 )
 
+const (
+	deployVAppParamsType   = "application/vnd.vmware.vcloud.deployVAppParams+xml"
+	undeployVAppParamsType = "application/vnd.vmware.vcloud.undeployVAppParams+xml"
+)
+
+// Undeploy power actions
+const (
+	UndeployDefault  = "default"
+	UndeployPowerOff = "powerOff"
+	UndeploySuspend  = "suspend"
+	UndeployShutdown = "shutdown"
+	UndeployForce    = "force"
+)
+
 // VApp ...
 type VApp struct {
 	Connector *Connector `xml:"-"`
@@ -17,6 +32,9 @@ type VApp struct {
 	Deployed  bool       `xml:"deployed,attr"`
 	Links     []t.Link   `xml:"Link"`
 	Tasks     *Tasks     `xml:"Tasks"`
+	Children  struct {
+		VMs []*VM `xml:"Vm"`
+	} `xml:"Children"`
 	//NetworkConfig t.
 }
 
@@ -49,8 +67,95 @@ func parseVApp(d *[]byte) *VApp {
 
 // GetTasks ...
 func (v *VApp) GetTasks() []Task {
+	if v.Tasks == nil {
+		return nil
+	}
 	for i := 0; i < len(v.Tasks.Task); i++ {
 		v.Tasks.Task[i].Connector = v.Connector
 	}
 	return v.Tasks.Task
 }
+
+// VMs ...
+func (v *VApp) VMs() []*VM {
+	for _, vm := range v.Children.VMs {
+		vm.Connector = v.Connector
+	}
+	return v.Children.VMs
+}
+
+// GetVM ...
+func (v *VApp) GetVM(name string) (*VM, error) {
+	for _, vm := range v.Children.VMs {
+		if vm.Name == name {
+			return NewVM(v.Connector, vm.Href)
+		}
+	}
+	return nil, fmt.Errorf("could not find vm %s in vapp %s", name, v.Name)
+}
+
+// PowerOn ...
+func (v *VApp) PowerOn() (*Task, error) {
+	return v.action("power/action/powerOn", nil, "")
+}
+
+// PowerOff ...
+func (v *VApp) PowerOff() (*Task, error) {
+	return v.action("power/action/powerOff", nil, "")
+}
+
+// Suspend ...
+func (v *VApp) Suspend() (*Task, error) {
+	return v.action("power/action/suspend", nil, "")
+}
+
+// Reset ...
+func (v *VApp) Reset() (*Task, error) {
+	return v.action("power/action/reset", nil, "")
+}
+
+// Reboot ...
+func (v *VApp) Reboot() (*Task, error) {
+	return v.action("power/action/reboot", nil, "")
+}
+
+// Shutdown ...
+func (v *VApp) Shutdown() (*Task, error) {
+	return v.action("power/action/shutdown", nil, "")
+}
+
+// Deploy ...
+func (v *VApp) Deploy(params *t.DeployVAppParams) (*Task, error) {
+	if params == nil {
+		params = &t.DeployVAppParams{}
+	}
+
+	data, err := xml.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.action("action/deploy", data, deployVAppParamsType)
+}
+
+// Undeploy ...
+func (v *VApp) Undeploy(params *t.UndeployVAppParams) (*Task, error) {
+	if params == nil {
+		params = &t.UndeployVAppParams{UndeployPowerAction: UndeployPowerOff}
+	}
+
+	data, err := xml.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.action("action/undeploy", data, undeployVAppParamsType)
+}
+
+func (v *VApp) action(action string, data []byte, contentType string) (*Task, error) {
+	href := findActionLink(v.Links, action)
+	if href == "" {
+		return nil, fmt.Errorf("vapp %s does not support action %s in its current state", v.Name, action)
+	}
+	return v.Connector.PostTask(href, data, contentType)
+}
@@ -0,0 +1,219 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	types "git.r3labs.io/libraries/go-vcloud/types"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func unsupportedMediaType(w http.ResponseWriter) {
+	message, _ := loadFixture("fixtures/unsupportedmediatype.xml")
+	http.Error(w, string(message), 415)
+}
+
+func instantiateVAppHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != instantiateVAppTemplateParamsType {
+		unsupportedMediaType(w)
+		return
+	}
+
+	var params types.InstantiateVApp
+	err := xml.Unmarshal(*parseRequest(r), &params)
+	if err != nil || params.Source.Href == "" {
+		message, _ := loadFixture("fixtures/eoferror.xml")
+		http.Error(w, string(message), 400)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.vmware.vcloud.vApp+xml")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `<VApp xmlns="http://www.vmware.com/vcloud/v1.5" deployed="%t" status="0" name="%s" href="https://%s/api/vApp/vapp-1">
+    <Link rel="up" type="application/vnd.vmware.vcloud.vdc+xml" href="https://%s/api/vdc/%s"/>
+    <Tasks>
+        <Task status="running" operationName="vdcInstantiateVapp" name="task" href="https://%s/api/task/success"/>
+    </Tasks>
+</VApp>`, params.Deploy, params.Name, r.Host, r.Host, ps.ByName("id"), r.Host)
+}
+
+// vappActionHandler accepts power, deploy and undeploy actions, returning a
+// task named after the action that was requested.
+func vappActionHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	action := ps.ByName("action")
+	body := *parseRequest(r)
+	ct := r.Header.Get("Content-Type")
+
+	switch action {
+	case "deploy":
+		var params types.DeployVAppParams
+		if ct != deployVAppParamsType || xml.Unmarshal(body, &params) != nil {
+			unsupportedMediaType(w)
+			return
+		}
+	case "undeploy":
+		var params types.UndeployVAppParams
+		if ct != undeployVAppParamsType || xml.Unmarshal(body, &params) != nil {
+			unsupportedMediaType(w)
+			return
+		}
+		action = action + ":" + params.UndeployPowerAction
+	default:
+		if ct != "" || len(body) > 0 {
+			unsupportedMediaType(w)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.vmware.vcloud.task+xml")
+	w.WriteHeader(202)
+	fmt.Fprintf(w, `<Task xmlns="http://www.vmware.com/vcloud/v1.5" name="task" status="queued" operationName="%s" href="https://%s/api/task/success"/>`, action, r.Host)
+}
+
+func TestCreateVApp(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a datacenter", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		d := &Datacenter{Connector: c, Links: []types.Link{
+			{Rel: "add", Type: instantiateVAppTemplateParamsType, Href: fmt.Sprintf("https://%s/api/vdc/1/action/instantiateVAppTemplate", tsurl.Host)},
+		}}
+
+		request := &types.InstantiateVApp{Name: "web", Deploy: true}
+		request.Source.Href = fmt.Sprintf("https://%s/api/vAppTemplate/vappTemplate-1", tsurl.Host)
+
+		Convey("When instantiating a vapp template", func() {
+			v, err := d.CreateVApp(request)
+			Convey("The created vapp should be returned", func() {
+				So(err, ShouldBeNil)
+				So(v.Name, ShouldEqual, "web")
+				So(v.Deployed, ShouldBeTrue)
+				So(v.Connector, ShouldEqual, c)
+			})
+			Convey("Its creation task should be available", func() {
+				tasks := v.GetTasks()
+				So(tasks, ShouldHaveLength, 1)
+				So(tasks[0].OperationName, ShouldEqual, "vdcInstantiateVapp")
+				So(tasks[0].Connector, ShouldEqual, c)
+				So(tasks[0].Wait(), ShouldBeNil)
+			})
+		})
+
+		Convey("When the template is invalid", func() {
+			request.Source.Href = ""
+			v, err := d.CreateVApp(request)
+			Convey("An error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(v, ShouldBeNil)
+			})
+		})
+
+		Convey("When the datacenter has no instantiate link", func() {
+			d.Links = nil
+			v, err := d.CreateVApp(request)
+			Convey("An error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(v, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestVAppActions(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a vapp", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		href := fmt.Sprintf("https://%s/api/vApp/vapp-1", tsurl.Host)
+		v := &VApp{Connector: c, Name: "web", Href: href}
+		for _, action := range []string{"powerOn", "powerOff", "suspend", "reset", "reboot", "shutdown"} {
+			v.Links = append(v.Links, types.Link{Rel: action, Href: href + "/power/action/" + action})
+		}
+		v.Links = append(v.Links,
+			types.Link{Rel: "deploy", Type: deployVAppParamsType, Href: href + "/action/deploy"},
+			types.Link{Rel: "undeploy", Type: undeployVAppParamsType, Href: href + "/action/undeploy"},
+		)
+
+		Convey("When changing its power state", func() {
+			actions := map[string]func() (*Task, error){
+				"powerOn":  v.PowerOn,
+				"powerOff": v.PowerOff,
+				"suspend":  v.Suspend,
+				"reset":    v.Reset,
+				"reboot":   v.Reboot,
+				"shutdown": v.Shutdown,
+			}
+			for action, fn := range actions {
+				task, err := fn()
+				So(err, ShouldBeNil)
+				So(task.OperationName, ShouldEqual, action)
+				So(task.Connector, ShouldEqual, c)
+			}
+		})
+
+		Convey("When deploying it", func() {
+			task, err := v.Deploy(&types.DeployVAppParams{PowerOn: true})
+			Convey("The deploy params should be posted", func() {
+				So(err, ShouldBeNil)
+				So(task.OperationName, ShouldEqual, "deploy")
+			})
+		})
+
+		Convey("When undeploying it with the default options", func() {
+			task, err := v.Undeploy(nil)
+			Convey("It should be powered off", func() {
+				So(err, ShouldBeNil)
+				So(task.OperationName, ShouldEqual, "undeploy:"+UndeployPowerOff)
+			})
+		})
+
+		Convey("When undeploying it with a shutdown", func() {
+			task, err := v.Undeploy(&types.UndeployVAppParams{UndeployPowerAction: UndeployShutdown})
+			Convey("The power action should be posted", func() {
+				So(err, ShouldBeNil)
+				So(task.OperationName, ShouldEqual, "undeploy:"+UndeployShutdown)
+			})
+		})
+
+		Convey("When the action is not available", func() {
+			v.Links = nil
+			task, err := v.PowerOn()
+			Convey("An error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(task, ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,312 @@
+package vcloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+
+	t "git.r3labs.io/libraries/go-vcloud/types"
+)
+
+const (
+	rasdItemType      = "application/vnd.vmware.vcloud.rasdItem+xml"
+	rasdItemsListType = "application/vnd.vmware.vcloud.rasdItemsList+xml"
+	vcloudNamespace   = "http://www.vmware.com/vcloud/v1.5"
+)
+
+// Virtual hardware resource types
+const (
+	ResourceTypeCPU            = 3
+	ResourceTypeMemory         = 4
+	ResourceTypeIDEController  = 5
+	ResourceTypeSCSIController = 6
+	ResourceTypeDisk           = 17
+	ResourceTypeSATAController = 20
+)
+
+// VM ...
+type VM struct {
+	Connector              *Connector                `xml:"-"`
+	XMLName                xml.Name                  `xml:"Vm"`
+	ID                     string                    `xml:"id,attr"`
+	Name                   string                    `xml:"name,attr"`
+	Href                   string                    `xml:"href,attr"`
+	Status                 string                    `xml:"status,attr"`
+	Deployed               bool                      `xml:"deployed,attr"`
+	Links                  []t.Link                  `xml:"Link"`
+	Tasks                  *Tasks                    `xml:"Tasks"`
+	VirtualHardwareSection *t.VirtualHardwareSection `xml:"VirtualHardwareSection"`
+}
+
+// NewVM ...
+func NewVM(c *Connector, href string) (*VM, error) {
+	resp, err := c.Get(href)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := parseVM(data)
+	vm.Connector = c
+
+	return vm, nil
+}
+
+func parseVM(d *[]byte) *VM {
+	vm := VM{}
+	err := xml.Unmarshal(*d, &vm)
+	if err != nil {
+		log.Println(err)
+	}
+	return &vm
+}
+
+// Reload ...
+func (vm *VM) Reload() error {
+	updated, err := NewVM(vm.Connector, vm.Href)
+	if err != nil {
+		return err
+	}
+	*vm = *updated
+	return nil
+}
+
+// GetTasks ...
+func (vm *VM) GetTasks() []Task {
+	if vm.Tasks == nil {
+		return nil
+	}
+	for i := 0; i < len(vm.Tasks.Task); i++ {
+		vm.Tasks.Task[i].Connector = vm.Connector
+	}
+	return vm.Tasks.Task
+}
+
+// CPUs ...
+func (vm *VM) CPUs() int {
+	item := vm.findItem(ResourceTypeCPU)
+	if item == nil {
+		return 0
+	}
+	return int(item.VirtualQuantity)
+}
+
+// SetCPUs ...
+func (vm *VM) SetCPUs(count int) error {
+	item := vm.findItem(ResourceTypeCPU)
+	if item == nil {
+		return fmt.Errorf("vm %s has no cpu hardware item", vm.Name)
+	}
+	item.VirtualQuantity = int64(count)
+	item.ElementName = fmt.Sprintf("%d virtual CPU(s)", count)
+	return nil
+}
+
+// CoresPerSocket ...
+func (vm *VM) CoresPerSocket() int {
+	item := vm.findItem(ResourceTypeCPU)
+	if item == nil || item.CoresPerSocket == nil {
+		return 0
+	}
+	return item.CoresPerSocket.Value
+}
+
+// SetCoresPerSocket ...
+func (vm *VM) SetCoresPerSocket(cores int) error {
+	item := vm.findItem(ResourceTypeCPU)
+	if item == nil {
+		return fmt.Errorf("vm %s has no cpu hardware item", vm.Name)
+	}
+	if item.CoresPerSocket == nil {
+		item.CoresPerSocket = &t.CoresPerSocket{Required: "false"}
+	}
+	item.CoresPerSocket.Value = cores
+	return nil
+}
+
+// MemoryMB ...
+func (vm *VM) MemoryMB() int {
+	item := vm.findItem(ResourceTypeMemory)
+	if item == nil {
+		return 0
+	}
+	return int(item.VirtualQuantity)
+}
+
+// SetMemoryMB ...
+func (vm *VM) SetMemoryMB(mb int) error {
+	item := vm.findItem(ResourceTypeMemory)
+	if item == nil {
+		return fmt.Errorf("vm %s has no memory hardware item", vm.Name)
+	}
+	item.VirtualQuantity = int64(mb)
+	item.ElementName = fmt.Sprintf("%d MB of memory", mb)
+	return nil
+}
+
+// Disks ...
+func (vm *VM) Disks() []t.RasdItem {
+	var disks []t.RasdItem
+	for _, item := range vm.hardwareItems() {
+		if item.ResourceType == ResourceTypeDisk {
+			disks = append(disks, item)
+		}
+	}
+	return disks
+}
+
+// SetDiskSize ...
+func (vm *VM) SetDiskSize(name string, mb int) error {
+	items := vm.hardwareItems()
+	for i := range items {
+		if items[i].ResourceType != ResourceTypeDisk || items[i].ElementName != name {
+			continue
+		}
+		if len(items[i].HostResource) < 1 {
+			return fmt.Errorf("disk %s has no host resource", name)
+		}
+		if mb < items[i].HostResource[0].Capacity {
+			return fmt.Errorf("disk %s cannot be shrunk from %d MB to %d MB", name, items[i].HostResource[0].Capacity, mb)
+		}
+		items[i].HostResource[0].Capacity = mb
+		if items[i].VirtualQuantity > 0 {
+			items[i].VirtualQuantity = int64(mb) * 1024 * 1024
+		}
+		return nil
+	}
+	return fmt.Errorf("could not find disk %s on vm %s", name, vm.Name)
+}
+
+// AddDisk adds a disk of the given size, attached to the same controller as
+// the vm's existing disks.
+func (vm *VM) AddDisk(mb int) error {
+	disks := vm.Disks()
+	if len(disks) < 1 {
+		return fmt.Errorf("vm %s has no existing disk to base a new disk on", vm.Name)
+	}
+
+	if len(disks[0].HostResource) < 1 {
+		return fmt.Errorf("disk %s has no host resource to base a new disk on", disks[0].ElementName)
+	}
+
+	disk := disks[0]
+	disk.Href = ""
+	disk.Type = ""
+	disk.Links = nil
+	disk.HostResource = []t.HostResource{disks[0].HostResource[0]}
+	disk.HostResource[0].Capacity = mb
+	if disk.VirtualQuantity > 0 {
+		disk.VirtualQuantity = int64(mb) * 1024 * 1024
+	}
+
+	var address, instance int
+	for _, item := range vm.hardwareItems() {
+		if item.InstanceID > instance {
+			instance = item.InstanceID
+		}
+		var a int
+		if item.ResourceType == ResourceTypeDisk && item.Parent == disk.Parent {
+			fmt.Sscanf(item.AddressOnParent, "%d", &a)
+			if a >= address {
+				address = a + 1
+			}
+		}
+	}
+
+	disk.InstanceID = instance + 1
+	disk.AddressOnParent = fmt.Sprintf("%d", address)
+	disk.ElementName = fmt.Sprintf("Hard disk %d", len(disks)+1)
+
+	vm.VirtualHardwareSection.Items = append(vm.VirtualHardwareSection.Items, disk)
+
+	return nil
+}
+
+// RemoveDisk ...
+func (vm *VM) RemoveDisk(name string) error {
+	items := vm.hardwareItems()
+	for i := range items {
+		if items[i].ResourceType == ResourceTypeDisk && items[i].ElementName == name {
+			vm.VirtualHardwareSection.Items = append(items[:i], items[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("could not find disk %s on vm %s", name, vm.Name)
+}
+
+// UpdateCPU ...
+func (vm *VM) UpdateCPU() (*Task, error) {
+	return vm.updateItem(ResourceTypeCPU, "cpu")
+}
+
+// UpdateMemory ...
+func (vm *VM) UpdateMemory() (*Task, error) {
+	return vm.updateItem(ResourceTypeMemory, "memory")
+}
+
+// UpdateDisks ...
+func (vm *VM) UpdateDisks() (*Task, error) {
+	list := t.RasdItemsList{
+		Href: vm.hardwareHref("disks"),
+		Type: rasdItemsListType,
+	}
+
+	for _, item := range vm.hardwareItems() {
+		switch item.ResourceType {
+		case ResourceTypeIDEController, ResourceTypeSCSIController, ResourceTypeSATAController, ResourceTypeDisk:
+			item.XMLName = xml.Name{Space: vcloudNamespace, Local: "Item"}
+			list.Items = append(list.Items, item)
+		}
+	}
+
+	data, err := xml.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return vm.Connector.PutTask(list.Href, data, rasdItemsListType)
+}
+
+func (vm *VM) updateItem(resourceType int, resource string) (*Task, error) {
+	item := vm.findItem(resourceType)
+	if item == nil {
+		return nil, fmt.Errorf("vm %s has no %s hardware item", vm.Name, resource)
+	}
+
+	update := *item
+	update.XMLName = xml.Name{Space: vcloudNamespace, Local: "Item"}
+	update.Href = vm.hardwareHref(resource)
+	update.Type = rasdItemType
+
+	data, err := xml.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+
+	return vm.Connector.PutTask(update.Href, data, rasdItemType)
+}
+
+func (vm *VM) hardwareHref(resource string) string {
+	return fmt.Sprintf("%s/virtualHardwareSection/%s", vm.Href, resource)
+}
+
+func (vm *VM) hardwareItems() []t.RasdItem {
+	if vm.VirtualHardwareSection == nil {
+		return nil
+	}
+	return vm.VirtualHardwareSection.Items
+}
+
+func (vm *VM) findItem(resourceType int) *t.RasdItem {
+	items := vm.hardwareItems()
+	for i := range items {
+		if items[i].ResourceType == resourceType {
+			return &items[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package vcloud
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	types "git.r3labs.io/libraries/go-vcloud/types"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const vmID = "vm-9f3d1c2e-4b5a-4e7f-8c1d-2a3b4c5d6e7f"
+
+func vmHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if !auth(w, r) {
+		return
+	}
+
+	if ps.ByName("id") != vmID {
+		notFoundHandler(w, r)
+		return
+	}
+
+	data, _ := loadFixture("fixtures/vm.xml")
+	w.Header().Set("Content-Type", "application/vnd.vmware.vcloud.vm+xml")
+	w.Write(bytes.Replace(data, []byte("vcloud.example.com"), []byte(r.Host), -1))
+}
+
+func TestVMHardware(t *testing.T) {
+	setup()
+	defer teardown()
+	tsurl, _ := url.Parse(server.URL)
+
+	Convey("Given a vm", t, func() {
+		cf := Config{
+			URL:           tsurl.Host,
+			Username:      "test@test",
+			Password:      "test",
+			SSLSkipVerify: true,
+		}
+		c := NewConnector(&cf)
+		_ = c.Authenticate()
+
+		href := fmt.Sprintf("https://%s/api/vApp/%s", tsurl.Host, vmID)
+		vm, err := NewVM(c, href)
+		So(err, ShouldBeNil)
+
+		Convey("Its virtual hardware should be parsed", func() {
+			So(vm.Name, ShouldEqual, "web-1")
+			So(vm.VirtualHardwareSection.Items, ShouldHaveLength, 6)
+			So(vm.CPUs(), ShouldEqual, 2)
+			So(vm.CoresPerSocket(), ShouldEqual, 1)
+			So(vm.MemoryMB(), ShouldEqual, 2048)
+			disks := vm.Disks()
+			So(disks, ShouldHaveLength, 1)
+			So(disks[0].ElementName, ShouldEqual, "Hard disk 1")
+			So(disks[0].HostResource[0].Capacity, ShouldEqual, 16384)
+			So(disks[0].HostResource[0].BusSubType, ShouldEqual, "lsilogicsas")
+		})
+
+		Convey("When changing the cpu count", func() {
+			So(vm.SetCPUs(4), ShouldBeNil)
+			So(vm.SetCoresPerSocket(2), ShouldBeNil)
+			task, err := vm.UpdateCPU()
+			req := lastRequest()
+
+			Convey("The cpu item should be put to its sub-resource", func() {
+				So(err, ShouldBeNil)
+				So(req.Method, ShouldEqual, "PUT")
+				So(req.Path, ShouldEqual, "/api/vApp/"+vmID+"/virtualHardwareSection/cpu")
+				So(req.ContentType, ShouldEqual, rasdItemType)
+			})
+			Convey("The marshalled item should carry the new values", func() {
+				So(string(req.Body), ShouldStartWith, `<Item xmlns="http://www.vmware.com/vcloud/v1.5"`)
+				var item types.RasdItem
+				So(xml.Unmarshal(req.Body, &item), ShouldBeNil)
+				So(item.ResourceType, ShouldEqual, ResourceTypeCPU)
+				So(item.VirtualQuantity, ShouldEqual, 4)
+				So(item.ElementName, ShouldEqual, "4 virtual CPU(s)")
+				So(item.CoresPerSocket.Value, ShouldEqual, 2)
+			})
+			Convey("The update task should be returned", func() {
+				So(task.Status, ShouldEqual, TaskStatusQueued)
+				So(task.Connector, ShouldEqual, c)
+				So(task.Wait(), ShouldBeNil)
+			})
+		})
+
+		Convey("When changing the memory size", func() {
+			So(vm.SetMemoryMB(4096), ShouldBeNil)
+			task, err := vm.UpdateMemory()
+			req := lastRequest()
+
+			Convey("The memory item should be put to its sub-resource", func() {
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+				So(req.Path, ShouldEqual, "/api/vApp/"+vmID+"/virtualHardwareSection/memory")
+				So(req.ContentType, ShouldEqual, rasdItemType)
+				var item types.RasdItem
+				So(xml.Unmarshal(req.Body, &item), ShouldBeNil)
+				So(item.VirtualQuantity, ShouldEqual, 4096)
+				So(item.ElementName, ShouldEqual, "4096 MB of memory")
+			})
+		})
+
+		Convey("When growing a disk and adding another", func() {
+			So(vm.SetDiskSize("Hard disk 1", 20480), ShouldBeNil)
+			So(vm.AddDisk(10240), ShouldBeNil)
+			task, err := vm.UpdateDisks()
+			req := lastRequest()
+
+			Convey("The disk list should be put to its sub-resource", func() {
+				So(err, ShouldBeNil)
+				So(task, ShouldNotBeNil)
+				So(req.Method, ShouldEqual, "PUT")
+				So(req.Path, ShouldEqual, "/api/vApp/"+vmID+"/virtualHardwareSection/disks")
+				So(req.ContentType, ShouldEqual, rasdItemsListType)
+			})
+			Convey("Only controllers and disks should be sent", func() {
+				var list types.RasdItemsList
+				So(xml.Unmarshal(req.Body, &list), ShouldBeNil)
+				So(list.Items, ShouldHaveLength, 4)
+
+				var disks []types.RasdItem
+				for _, item := range list.Items {
+					So(item.XMLName.Space, ShouldEqual, "http://www.vmware.com/vcloud/v1.5")
+					if item.ResourceType == ResourceTypeDisk {
+						disks = append(disks, item)
+					}
+				}
+				So(disks, ShouldHaveLength, 2)
+				So(disks[0].HostResource[0].Capacity, ShouldEqual, 20480)
+				So(disks[0].VirtualQuantity, ShouldEqual, 20480*1024*1024)
+				So(disks[1].ElementName, ShouldEqual, "Hard disk 2")
+				So(disks[1].HostResource[0].Capacity, ShouldEqual, 10240)
+				So(disks[1].AddressOnParent, ShouldEqual, "1")
+				So(disks[1].InstanceID, ShouldEqual, 2001)
+				So(disks[1].Parent, ShouldEqual, "2")
+			})
+		})
+
+		Convey("When adding a disk alongside one without a host resource", func() {
+			for i := range vm.VirtualHardwareSection.Items {
+				if vm.VirtualHardwareSection.Items[i].ResourceType == ResourceTypeDisk {
+					vm.VirtualHardwareSection.Items[i].HostResource = nil
+				}
+			}
+			So(vm.AddDisk(10240), ShouldNotBeNil)
+			So(vm.Disks(), ShouldHaveLength, 1)
+		})
+
+		Convey("When shrinking a disk", func() {
+			So(vm.SetDiskSize("Hard disk 1", 8192), ShouldNotBeNil)
+		})
+
+		Convey("When removing a disk", func() {
+			So(vm.RemoveDisk("Hard disk 1"), ShouldBeNil)
+			So(vm.Disks(), ShouldHaveLength, 0)
+			So(vm.RemoveDisk("Hard disk 1"), ShouldNotBeNil)
+		})
+	})
+}